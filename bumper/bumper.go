@@ -2,10 +2,13 @@
 package bumper
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/jgautheron/codename-generator"
+	"github.com/jgautheron/gocha/changelog"
 	"github.com/jgautheron/gocha/message"
 	"github.com/jgautheron/gocha/repository"
 	"github.com/jgautheron/gocha/semver"
@@ -15,9 +18,41 @@ const (
 	Major = "major"
 	Minor = "minor"
 	Patch = "patch"
+
+	// Auto infers the bump level from the commits since the last tag
+	Auto = "auto"
+
+	// Pre-release bumps
+	PreMajor   = "premajor"
+	PreMinor   = "preminor"
+	PrePatch   = "prepatch"
+	PreRelease = "prerelease"
+	Release    = "release"
 )
 
-func Up(rp *repository.Repository, bmp string) {
+var (
+	errNoCommit      = errors.New("No commit has been found since the last tag")
+	errNotPreRelease = errors.New("The last tag is not a pre-release")
+	errNotHigher     = errors.New("The next version must be higher than the current one")
+)
+
+// Options holds the settings of a bump.
+type Options struct {
+	// PreReleaseID is the pre-release identifier, ex. alpha, beta, rc
+	PreReleaseID string
+
+	// DryRun prints the release details and changelog instead of tagging
+	DryRun bool
+
+	// Changelog holds the settings of the changelog printed by the dry run
+	Changelog changelog.Options
+
+	// NoPush only creates the tag locally, it can be pushed later with Push
+	NoPush bool
+}
+
+// Up computes the next version from the last tag, then tags and pushes it.
+func Up(rp *repository.Repository, bmp string, opts Options) {
 	var err error
 
 	lt, err := rp.GetLastTag()
@@ -27,26 +62,17 @@ func Up(rp *repository.Repository, bmp string) {
 
 	log.Debugf("Current tag is: %s", lt.Name)
 
-	var nxt string
-	switch string(bmp) {
-	case Major:
-		nxt, err = semver.GetNextMajorVersion(lt.Name)
+	if bmp == Auto {
+		bmp, err = getAutoBump(rp, lt)
 		if err != nil {
 			log.Fatal(err)
 		}
-		break
-	case Minor:
-		nxt, err = semver.GetNextMinorVersion(lt.Name)
-		if err != nil {
-			log.Fatal(err)
-		}
-		break
-	case Patch:
-		nxt, err = semver.GetNextPatchVersion(lt.Name)
-		if err != nil {
-			log.Fatal(err)
-		}
-		break
+		log.Infof("Inferred bump level: %s", bmp)
+	}
+
+	nxt, err := getNextVersion(lt.Version, bmp, opts.PreReleaseID)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	log.Debugf("Next tag is: %s", nxt)
@@ -67,9 +93,135 @@ func Up(rp *repository.Repository, bmp string) {
 		log.Fatal(err)
 	}
 
-	err = rp.CreateAndPushTag(nxt, msg.String())
+	if opts.DryRun {
+		fmt.Printf("Next version: %s\nCodename: %s\nTag message: %s\n", nxt, codename, msg)
+		if !opts.NoPush {
+			fmt.Printf("Remotes: %s\n", strings.Join(rp.GetRemotes(), ", "))
+		}
+
+		cl, err := changelog.Preview(rp, nxt.String(), opts.Changelog)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("\n%s", cl)
+		return
+	}
+
+	err = rp.CreateTag(nxt.String(), msg.String())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.NoPush {
+		log.Infof("The tag %s has been successfully created", nxt)
+		return
+	}
+
+	for _, rm := range rp.GetRemotes() {
+		err = rp.PushTag(rm, nxt.String())
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("The tag %s has been successfully pushed to %s", nxt, rm)
+	}
+}
+
+// Push pushes the given release tag, or the last one if none is given.
+// Useful when the tag was created with the NoPush option.
+func Push(rp *repository.Repository, tag string, opts Options) {
+	var err error
+	var tg repository.Tag
+
+	if len(tag) != 0 {
+		tg, err = rp.GetTag(tag)
+	} else {
+		tg, err = rp.GetLastTag()
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
-	log.Infof("The tag %s has been successfully pushed", nxt)
+
+	if opts.DryRun {
+		fmt.Printf("Tag to push to %s: %s\n", strings.Join(rp.GetRemotes(), ", "), tg.Name)
+		return
+	}
+
+	for _, rm := range rp.GetRemotes() {
+		err = rp.PushTag(rm, tg.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("The tag %s has been successfully pushed to %s", tg.Name, rm)
+	}
+}
+
+// getNextVersion returns the version following the given one for the given bump.
+func getNextVersion(v semver.Version, bmp string, id string) (semver.Version, error) {
+	var err error
+	var nxt semver.Version
+
+	switch bmp {
+	case Major:
+		nxt = v.NextMajor()
+	case Minor:
+		nxt = v.NextMinor()
+	case Patch:
+		nxt = v.NextPatch()
+	case PreMajor:
+		nxt, err = v.NextPreMajor(id)
+	case PreMinor:
+		nxt, err = v.NextPreMinor(id)
+	case PrePatch:
+		nxt, err = v.NextPrePatch(id)
+	case PreRelease:
+		nxt, err = v.NextPreRelease(id)
+	case Release:
+		if !v.IsPreRelease() {
+			return nxt, errNotPreRelease
+		}
+		nxt = v.Release()
+	default:
+		return nxt, fmt.Errorf("Unknown bump: %s", bmp)
+	}
+
+	if err != nil {
+		return nxt, err
+	}
+
+	// Switching to a lower pre-release identifier, ex. rc => alpha
+	if !v.LessThan(nxt) {
+		return nxt, errNotHigher
+	}
+
+	return nxt, nil
+}
+
+// getAutoBump walks the commits made since the given tag and infers
+// the bump level: major for breaking changes, minor for features
+// and patch for anything else.
+func getAutoBump(rp *repository.Repository, lt repository.Tag) (string, error) {
+	cmts, err := rp.GetCommitListSinceTag(lt)
+	if err != nil {
+		return "", err
+	}
+
+	if len(cmts) == 0 {
+		return "", errNoCommit
+	}
+
+	return getBumpFromMessages(message.GetMessages(cmts)), nil
+}
+
+// getBumpFromMessages returns the bump level matching the given messages.
+func getBumpFromMessages(ms []message.Message) string {
+	bmp := Patch
+	for _, msg := range ms {
+		if msg.Breaking {
+			return Major
+		}
+		if msg.Type == message.Feat {
+			bmp = Minor
+		}
+	}
+	return bmp
 }
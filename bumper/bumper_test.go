@@ -0,0 +1,55 @@
+package bumper
+
+import (
+	"testing"
+
+	"github.com/jgautheron/gocha/message"
+	"github.com/jgautheron/gocha/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBumpFromMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	var bmpTests = []struct {
+		in       []message.Message
+		expected string
+	}{
+		{[]message.Message{{Type: message.Fix}, {Type: message.Docs}}, Patch},
+		{[]message.Message{{Type: message.Fix}, {Type: message.Feat}}, Minor},
+		{[]message.Message{{Type: message.Feat}, {Type: message.Fix, Breaking: true}}, Major},
+		{nil, Patch},
+	}
+
+	for _, tt := range bmpTests {
+		assert.Equal(tt.expected, getBumpFromMessages(tt.in))
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	var nxtTests = []struct {
+		version, bump, id string
+		expected          string
+		fails             bool
+	}{
+		{"1.2.3", Major, "", "2.0.0", false},
+		{"1.2.3", PreMinor, "rc", "1.3.0-rc.1", false},
+		{"1.3.0-rc.1", PreMinor, "rc", "1.3.0-rc.2", false},
+		{"1.3.0-rc.2", Release, "", "1.3.0", false},
+		{"1.3.0", Release, "", "", true},
+		{"1.3.0-rc.2", PreRelease, "alpha", "", true},
+		{"1.3.0", "foo", "", "", true},
+	}
+
+	for _, tt := range nxtTests {
+		nxt, err := getNextVersion(semver.MustParse(tt.version), tt.bump, tt.id)
+		if tt.fails {
+			assert.NotNil(err)
+			continue
+		}
+		assert.Nil(err)
+		assert.Equal(tt.expected, nxt.String())
+	}
+}
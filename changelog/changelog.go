@@ -2,92 +2,450 @@
 package changelog
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 
 	"github.com/flosch/pongo2"
+	"github.com/jgautheron/gocha/forge"
 	"github.com/jgautheron/gocha/message"
 	"github.com/jgautheron/gocha/repository"
 )
 
 const (
-	templateFile  = "template/changelog-template.md"
-	changelogFile = "CHANGELOG.md"
+	changelogFile = "CHANGELOG"
+
+	// repoTemplateFile overrides the default template, relative to the repository
+	repoTemplateFile = ".gocha/changelog.tpl"
+
+	// unreleasedVersion is the heading of the commits made since the last tag
+	unreleasedVersion = "Unreleased"
 )
 
+var errUnreleasedRange = errors.New("The unreleased changes can't be combined with a revision range")
+
+// codenameRx matches the codename set in the tag message by the bump command.
+var codenameRx = regexp.MustCompile(`codename\(([^)]+)\)`)
+
+// Options holds the settings of the changelog generation.
+type Options struct {
+	// AppName is displayed in the changelog title
+	AppName string
+
+	// Tag is the release to generate the changelog for, defaults to the last tag
+	Tag string
+
+	// OutputFile is the changelog path, or its folder
+	OutputFile string
+
+	// DryRun prints the changelog instead of writing it
+	DryRun bool
+
+	// Unreleased generates the changelog of the commits made since the last tag
+	Unreleased bool
+
+	// From and To delimit an arbitrary revision range, tags, branches or SHAs
+	From, To string
+
+	// Initial includes all the commits from the root of the history up to the tag
+	Initial bool
+
+	// All generates the changelog of every release, newest first
+	All bool
+
+	// Prepend inserts the releases at the top of the existing changelog,
+	// replacing the ones already present
+	Prepend bool
+
+	// TemplateFile overrides the default Markdown template
+	TemplateFile string
+
+	// Format is the output format, Markdown by default
+	Format string
+
+	// Forge customizes the links to the repository hosting service
+	Forge forge.Options
+}
+
+// release holds the details of a changelog entry.
+type release struct {
+	Version  string
+	Codename string
+	Date     time.Time
+	Commits  []repository.Commit
+
+	// Ref is the revision the release points to, Previous the one it starts
+	// from, empty if the release holds the whole history
+	Ref, Previous string
+
+	// Filled from the commits before rendering
+	Sections []message.Section
+	Breaking []message.Message
+}
+
 // Generate will lookup the commits for the given tag and create a CHANGELOG.md file in the current path.
-func Generate(rp *repository.Repository, tag string, appName string, outputFile string) {
+func Generate(rp *repository.Repository, opts Options) {
 	var err error
-	var tg repository.Tag
+	var rls []release
+
+	ft, err := getFormat(opts.Format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.Prepend && !ft.Prependable {
+		log.Fatalf("The %s format cannot be prepended", opts.Format)
+	}
 
-	if tag != "" {
-		tg, err = rp.GetTag(tag)
+	if opts.All || ft.WholeFile {
+		rls, err = getAllReleases(rp)
+		if err == nil && ft.WholeFile {
+			var rl release
+			rl, err = getUnreleased(rp)
+			rls = append([]release{rl}, rls...)
+		}
 	} else {
-		tg, err = rp.GetLastTag()
+		var rl release
+		rl, err = getRelease(rp, opts)
+		rls = []release{rl}
 	}
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	cmts, err := rp.GetCommitListForTag(tg)
+	output, err := render(rp, ft, rls, opts)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	ms, err := message.GetMessageGroup(cmts)
+	outputFile, err := getOutputFile(opts.OutputFile, ft.Extension)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	url, err := rp.GetOriginURL()
+	if opts.Prepend {
+		if output, err = prependToFile(outputFile, output); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Print(string(output))
+		return
+	}
+
+	err = ioutil.WriteFile(outputFile, output, 0644)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	output, err := getFilledTemplate(pongo2.Context{
-		"appName":       appName,
-		"version":       tg.Name,
-		"message_group": ms,
-		"url":           url,
-	}, templateFile)
+	log.Infof("%s has been successfully created!", outputFile)
+}
+
+// getForge returns the forge hosting the main remote.
+func getForge(rp *repository.Repository, opts forge.Options) (*forge.Forge, error) {
+	remote, err := rp.GetOriginURL()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	return forge.New(remote, opts)
+}
+
+// linkIssues links the GitHub issue and merge request references,
+// the only ones without URL, to the forge if known.
+func linkIssues(fg *forge.Forge, ms []message.Message) {
+	if fg == nil {
+		return
+	}
+
+	for i := range ms {
+		for j := range ms[i].Issues {
+			is := &ms[i].Issues[j]
+			if len(is.URL) != 0 {
+				continue
+			}
+
+			if is.MergeRequest {
+				is.URL = fg.MergeRequestURL(is.ID)
+			} else {
+				is.URL = fg.IssueURL(is.ID)
+			}
+		}
 	}
+}
 
+// getOutputFile returns the changelog path, CHANGELOG
+// with the format extension is appended to the folders.
+func getOutputFile(outputFile, ext string) (string, error) {
 	fileInfo, err := os.Stat(outputFile)
+	if os.IsNotExist(err) {
+		return outputFile, nil
+	}
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	if fileInfo.IsDir() {
 		if outputFile[len(outputFile)-1:] != "/" {
 			outputFile += string(os.PathSeparator)
 		}
-		outputFile += changelogFile
+		outputFile += changelogFile + ext
 	}
 
-	err = ioutil.WriteFile(outputFile, output, 0644)
+	return outputFile, nil
+}
+
+// Preview renders the changelog of the commits made since the last tag
+// as the given upcoming version, ex. to print it before bumping.
+func Preview(rp *repository.Repository, version string, opts Options) ([]byte, error) {
+	ft, err := getFormat(opts.Format)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	log.Infof("%s has been successfully created!", outputFile)
+	rl, err := getUnreleased(rp)
+	if err != nil {
+		return nil, err
+	}
+	rl.Version = version
+
+	return render(rp, ft, []release{rl}, opts)
 }
 
-// getFilledTemplate returns the filled template as a slice of bytes.
+// render fills the sections of the given releases and renders them in the given format.
+func render(rp *repository.Repository, ft format, rls []release, opts Options) ([]byte, error) {
+	fg, err := getForge(rp, opts.Forge)
+	if err != nil {
+		log.Warnf("The changelog links are left out, the web URL of the repository is unknown: %s", err)
+	}
+
+	for i := range rls {
+		ms := message.GetMessages(rls[i].Commits)
+		linkIssues(fg, ms)
+		rls[i].Sections = ft.getSections(ms)
+		rls[i].Breaking = message.FilterBreaking(ms)
+	}
+
+	if ft.Marshal != nil {
+		return ft.Marshal(newDocument(opts.AppName, fg, rls))
+	}
+
+	tplFile := ""
+	if ft.Name == FormatMarkdown {
+		tplFile = getTemplateFile(rp, opts.TemplateFile)
+	}
+	ctxt := pongo2.Context{
+		"appName":    opts.AppName,
+		"releases":   rls,
+		"unreleased": unreleasedVersion,
+	}
+	if fg != nil {
+		ctxt["forge"] = fg
+		ctxt["url"] = fg.URL
+	}
+	return getFilledTemplate(ctxt, tplFile, ft.Template)
+}
+
+// getRelease looks up the commits of the release to generate the changelog for.
+func getRelease(rp *repository.Repository, opts Options) (release, error) {
+	withRange := len(opts.From) != 0 || len(opts.To) != 0
+	if opts.Unreleased && withRange {
+		return release{}, errUnreleasedRange
+	}
+	if opts.Unreleased {
+		return getUnreleased(rp)
+	}
+	if withRange {
+		return getRange(rp, opts.From, opts.To)
+	}
+
+	var err error
+	var tg repository.Tag
+
+	if opts.Tag != "" {
+		tg, err = rp.GetTag(opts.Tag)
+	} else {
+		tg, err = rp.GetLastTag()
+	}
+
+	if err != nil {
+		return release{}, err
+	}
+
+	if opts.Initial {
+		cmts, err := rp.GetCommitListUntilTag(tg)
+		if err != nil {
+			return release{}, err
+		}
+		return newTagRelease(tg, "", cmts), nil
+	}
+
+	var prev string
+	var cmts []repository.Commit
+
+	ptg, err := rp.GetPreviousTagFor(tg)
+	switch {
+	case repository.IsNoTagFound(err):
+		cmts, err = rp.GetCommitListUntilTag(tg)
+	case err != nil:
+		return release{}, err
+	default:
+		prev = ptg.Name
+		cmts, err = rp.GetCommitsBetween(prev, tg.Name)
+	}
+
+	if err != nil {
+		return release{}, err
+	}
+
+	return newTagRelease(tg, prev, cmts), nil
+}
+
+// getAllReleases returns the releases of all the semver tags, newest first.
+func getAllReleases(rp *repository.Repository) ([]release, error) {
+	tgs, err := rp.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	var rls []release
+	for idx := len(tgs) - 1; idx >= 0; idx-- {
+		// The tags are sorted, the first one holds the whole history up to it
+		var prev string
+		if idx > 0 {
+			prev = tgs[idx-1].Name
+		}
+
+		cmts, err := rp.GetCommitsBetween(prev, tgs[idx].Name)
+		if err != nil {
+			return nil, err
+		}
+		rls = append(rls, newTagRelease(tgs[idx], prev, cmts))
+	}
+
+	return rls, nil
+}
+
+// newTagRelease returns the release of the given tag,
+// the codename is read from the tag message.
+func newTagRelease(tg repository.Tag, prev string, cmts []repository.Commit) release {
+	return release{
+		Version:  tg.Name,
+		Codename: getCodename(tg.Message),
+		Date:     tg.Date,
+		Commits:  cmts,
+		Ref:      tg.Name,
+		Previous: prev,
+	}
+}
+
+// getCodename extracts the codename from the given tag message,
+// ex. "chore(release): v1.2.0 codename(brave-falcon)".
+func getCodename(msg string) string {
+	res := codenameRx.FindStringSubmatch(msg)
+	if len(res) == 0 {
+		return ""
+	}
+	return res[1]
+}
+
+// getUnreleased returns the commits made since the last tag,
+// or the whole history if there is no tag yet.
+func getUnreleased(rp *repository.Repository) (release, error) {
+	var prev string
+	var cmts []repository.Commit
+
+	lt, err := rp.GetLastTag()
+	switch {
+	case repository.IsNoTagFound(err):
+		log.Debug(err)
+		cmts, err = rp.GetCommitListForHead()
+	case err != nil:
+		return release{}, err
+	default:
+		prev = lt.Name
+		cmts, err = rp.GetCommitListSinceTag(lt)
+	}
+
+	if err != nil {
+		return release{}, err
+	}
+
+	return release{
+		Version:  unreleasedVersion,
+		Date:     time.Now(),
+		Commits:  cmts,
+		Ref:      "HEAD",
+		Previous: prev,
+	}, nil
+}
+
+// getRange returns the commits between the given revisions,
+// labelled after the range.
+func getRange(rp *repository.Repository, from, to string) (release, error) {
+	cmts, err := rp.GetCommitsBetween(from, to)
+	if err != nil {
+		return release{}, err
+	}
+
+	if len(to) == 0 {
+		to = "HEAD"
+	}
+	rl := release{Version: to, Date: time.Now(), Commits: cmts, Ref: to, Previous: from}
+	if len(from) != 0 {
+		rl.Version = fmt.Sprintf("%s..%s", from, to)
+	}
+
+	// The commits are sorted by time, the first one is the most recent
+	if len(cmts) != 0 {
+		rl.Date = cmts[0].Date
+	}
+
+	return rl, nil
+}
+
+// getTemplateFile returns the path of the custom template, if any:
+// the given one, or the one stored in the repository.
+func getTemplateFile(rp *repository.Repository, tplFile string) string {
+	if len(tplFile) != 0 {
+		return tplFile
+	}
+
+	rtf := filepath.Join(rp.GetRepository().Workdir(), repoTemplateFile)
+	if _, err := os.Stat(rtf); err == nil {
+		return rtf
+	}
+
+	return ""
+}
+
+// getFilledTemplate returns the filled template as a slice of bytes,
+// the default template is used if no template file is given.
 // Initially wanted to use here the stdlib's text/template but ran into issues
 // with the if instruction.
 // The template looks quite ugly because of the blank lines left by the tags.
 // https://code.djangoproject.com/ticket/2594 (WONTFIX)
 // https://github.com/flosch/pongo2/issues/94
-func getFilledTemplate(ctxt pongo2.Context, tplFile string) ([]byte, error) {
-	t := pongo2.Must(pongo2.FromFile(tplFile))
-	output, err := t.ExecuteBytes(ctxt)
+func getFilledTemplate(ctxt pongo2.Context, tplFile, defaultTpl string) ([]byte, error) {
+	var err error
+	var t *pongo2.Template
+
+	if len(tplFile) != 0 {
+		log.Debugf("Using the template %s", tplFile)
+		t, err = pongo2.FromFile(tplFile)
+	} else {
+		t, err = pongo2.FromString(defaultTpl)
+	}
+
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return output, nil
+
+	return t.ExecuteBytes(ctxt)
 }
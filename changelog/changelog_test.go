@@ -0,0 +1,48 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/jgautheron/gocha/forge"
+	"github.com/jgautheron/gocha/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCodename(t *testing.T) {
+	assert := assert.New(t)
+
+	var codenameTests = []struct {
+		in       string
+		expected string
+	}{
+		{"chore(release): v1.2.0 codename(brave-falcon)", "brave-falcon"},
+		{"chore(release): v2.0.0-rc.1 codename(quiet_otter)", "quiet_otter"},
+		{"chore(release): v1.2.0", ""},
+		{"", ""},
+	}
+
+	for _, tt := range codenameTests {
+		assert.Equal(tt.expected, getCodename(tt.in))
+	}
+}
+
+func TestLinkIssues(t *testing.T) {
+	assert := assert.New(t)
+
+	fg, err := forge.New("git@gitlab.com:org/repo.git", forge.Options{})
+	assert.Nil(err)
+
+	ms := []message.Message{{Issues: []message.Issue{
+		{Ref: "#12", ID: "12"},
+		{Ref: "!34", ID: "34", MergeRequest: true},
+		{Ref: "JIRA-56", ID: "JIRA-56", URL: "https://jira.example.com/browse/JIRA-56"},
+	}}}
+
+	linkIssues(nil, ms)
+	assert.Empty(ms[0].Issues[0].URL)
+
+	linkIssues(fg, ms)
+	assert.Equal("https://gitlab.com/org/repo/-/issues/12", ms[0].Issues[0].URL)
+	assert.Equal("https://gitlab.com/org/repo/-/merge_requests/34", ms[0].Issues[1].URL)
+	assert.Equal("https://jira.example.com/browse/JIRA-56", ms[0].Issues[2].URL)
+}
@@ -0,0 +1,209 @@
+package changelog
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jgautheron/gocha/forge"
+	"github.com/jgautheron/gocha/message"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+
+	// FormatKeepAChangelog follows https://keepachangelog.com
+	FormatKeepAChangelog = "keepachangelog"
+)
+
+var errUnknownFormat = errors.New("The given changelog format is not supported")
+
+// format describes how the changelog is rendered: either by a template,
+// or by marshalling the structured document.
+type format struct {
+	Name      string
+	Extension string
+	Template  string
+	Marshal   func(v interface{}) ([]byte, error)
+
+	// Sections groups the messages of a release, by type by default
+	Sections func(ms []message.Message) []message.Section
+
+	// Prependable formats hold the release markers
+	Prependable bool
+
+	// WholeFile formats always render every release,
+	// starting with the unreleased commits
+	WholeFile bool
+}
+
+var formats = []format{
+	{Name: FormatMarkdown, Extension: ".md", Template: markdownTemplate, Prependable: true},
+	{Name: FormatHTML, Extension: ".html", Template: htmlTemplate, Prependable: true},
+	{Name: FormatText, Extension: ".txt", Template: textTemplate},
+	{Name: FormatJSON, Extension: ".json", Marshal: marshalJSON},
+	{Name: FormatYAML, Extension: ".yaml", Marshal: yaml.Marshal},
+	{
+		Name:      FormatKeepAChangelog,
+		Extension: ".md",
+		Template:  keepAChangelogTemplate,
+		Sections:  getKeepAChangelogSections,
+		WholeFile: true,
+	},
+}
+
+// getFormat returns the given format, Markdown by default.
+func getFormat(name string) (format, error) {
+	if len(name) == 0 {
+		name = FormatMarkdown
+	}
+
+	for _, ft := range formats {
+		if ft.Name == name {
+			return ft, nil
+		}
+	}
+
+	return format{}, errUnknownFormat
+}
+
+// getSections groups the given messages in sections.
+func (ft format) getSections(ms []message.Message) []message.Section {
+	if ft.Sections != nil {
+		return ft.Sections(ms)
+	}
+	return message.GetSections(ms)
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// document is the structured changelog, for the JSON and YAML formats.
+type document struct {
+	AppName  string            `json:"app_name" yaml:"app_name"`
+	URL      string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Releases []documentRelease `json:"releases" yaml:"releases"`
+}
+
+type documentRelease struct {
+	Version    string            `json:"version" yaml:"version"`
+	Codename   string            `json:"codename,omitempty" yaml:"codename,omitempty"`
+	Date       time.Time         `json:"date" yaml:"date"`
+	CompareURL string            `json:"compare_url,omitempty" yaml:"compare_url,omitempty"`
+	Breaking   []documentMessage `json:"breaking,omitempty" yaml:"breaking,omitempty"`
+	Groups     []documentGroup   `json:"groups" yaml:"groups"`
+}
+
+// documentGroup matches a changelog section, ex. "Bug Fixes".
+type documentGroup struct {
+	Type   string          `json:"type" yaml:"type"`
+	Title  string          `json:"title" yaml:"title"`
+	Scopes []documentScope `json:"scopes" yaml:"scopes"`
+}
+
+type documentScope struct {
+	Scope    string            `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Messages []documentMessage `json:"messages" yaml:"messages"`
+}
+
+type documentMessage struct {
+	ID                  string          `json:"id" yaml:"id"`
+	Type                string          `json:"type" yaml:"type"`
+	Scope               string          `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Subject             string          `json:"subject" yaml:"subject"`
+	Body                string          `json:"body,omitempty" yaml:"body,omitempty"`
+	Breaking            bool            `json:"breaking,omitempty" yaml:"breaking,omitempty"`
+	BreakingDescription string          `json:"breaking_description,omitempty" yaml:"breaking_description,omitempty"`
+	Date                time.Time       `json:"date" yaml:"date"`
+	URL                 string          `json:"url,omitempty" yaml:"url,omitempty"`
+	Issues              []documentIssue `json:"issues,omitempty" yaml:"issues,omitempty"`
+}
+
+type documentIssue struct {
+	Ref string `json:"ref" yaml:"ref"`
+	ID  string `json:"id" yaml:"id"`
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	MergeRequest bool `json:"merge_request,omitempty" yaml:"merge_request,omitempty"`
+}
+
+// newDocument builds the structured changelog of the given releases.
+func newDocument(appName string, fg *forge.Forge, rls []release) document {
+	doc := document{AppName: appName, Releases: []documentRelease{}}
+	if fg != nil {
+		doc.URL = fg.URL
+	}
+
+	for _, rl := range rls {
+		dr := documentRelease{
+			Version:  rl.Version,
+			Codename: rl.Codename,
+			Date:     rl.Date,
+			Breaking: newDocumentMessages(fg, rl.Breaking),
+			Groups:   []documentGroup{},
+		}
+		if fg != nil && len(rl.Previous) != 0 {
+			dr.CompareURL = fg.CompareURL(rl.Previous, rl.Ref)
+		}
+
+		for _, sc := range rl.Sections {
+			dg := documentGroup{Type: sc.Type, Title: sc.Title}
+			for _, sg := range sc.Scopes {
+				// The messages without scope are not grouped under a placeholder
+				scope := sg.Scope
+				if scope == message.NoScope {
+					scope = ""
+				}
+				dg.Scopes = append(dg.Scopes, documentScope{
+					Scope:    scope,
+					Messages: newDocumentMessages(fg, sg.Messages),
+				})
+			}
+			dr.Groups = append(dr.Groups, dg)
+		}
+
+		doc.Releases = append(doc.Releases, dr)
+	}
+
+	return doc
+}
+
+func newDocumentMessages(fg *forge.Forge, ms []message.Message) []documentMessage {
+	var dms []documentMessage
+	for _, m := range ms {
+		var dis []documentIssue
+		for _, is := range m.Issues {
+			dis = append(dis, documentIssue{Ref: is.Ref, ID: is.ID, URL: is.URL, MergeRequest: is.MergeRequest})
+		}
+
+		var url string
+		if fg != nil {
+			url = fg.CommitURL(m.ID)
+		}
+
+		dms = append(dms, documentMessage{
+			ID:                  m.ID,
+			Type:                m.Type.String(),
+			Scope:               m.Scope,
+			Subject:             m.Subject,
+			Body:                m.Body,
+			Breaking:            m.Breaking,
+			BreakingDescription: m.BreakingDescription,
+			Date:                m.Date,
+			URL:                 url,
+			Issues:              dis,
+		})
+	}
+	return dms
+}
@@ -0,0 +1,81 @@
+package changelog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jgautheron/gocha/forge"
+	"github.com/jgautheron/gocha/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	ft, err := getFormat("")
+	assert.Nil(err)
+	assert.Equal(FormatMarkdown, ft.Name)
+	assert.Equal(".md", ft.Extension)
+
+	for _, name := range []string{FormatMarkdown, FormatHTML, FormatText, FormatJSON, FormatYAML} {
+		ft, err = getFormat(name)
+		assert.Nil(err)
+		assert.Equal(name, ft.Name)
+		assert.True(len(ft.Template) != 0 || ft.Marshal != nil)
+	}
+
+	_, err = getFormat("pdf")
+	assert.Equal(errUnknownFormat, err)
+}
+
+func TestNewDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	ms := []message.Message{
+		{Type: message.Feat, Scope: "api", Subject: "add the v2 endpoints", ID: "0123456789abcdef"},
+		{Type: message.Fix, Subject: "handle empty tags", ID: "fedcba9876543210", Breaking: true, BreakingDescription: "tags are required"},
+	}
+	fg, err := forge.New("git@github.com:jgautheron/gocha.git", forge.Options{})
+	assert.Nil(err)
+
+	doc := newDocument("gocha", fg, []release{{
+		Version:  "1.2.0",
+		Codename: "brave-falcon",
+		Date:     time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC),
+		Ref:      "1.2.0",
+		Previous: "1.1.0",
+		Sections: message.GetSections(ms),
+		Breaking: message.FilterBreaking(ms),
+	}})
+
+	assert.Len(doc.Releases, 1)
+	dr := doc.Releases[0]
+	assert.Equal("1.2.0", dr.Version)
+	assert.Equal("https://github.com/jgautheron/gocha/compare/1.1.0...1.2.0", dr.CompareURL)
+	assert.Equal("brave-falcon", dr.Codename)
+	assert.Len(dr.Breaking, 1)
+	assert.Equal("fedcba9876543210", dr.Breaking[0].ID)
+	assert.Equal("https://github.com/jgautheron/gocha/commit/fedcba9876543210", dr.Breaking[0].URL)
+	assert.Len(dr.Groups, 2)
+	assert.Equal("feat", dr.Groups[0].Type)
+	assert.Equal("api", dr.Groups[0].Scopes[0].Scope)
+	assert.Equal("add the v2 endpoints", dr.Groups[0].Scopes[0].Messages[0].Subject)
+	assert.Empty(dr.Groups[1].Scopes[0].Scope)
+
+	out, err := marshalJSON(doc)
+	assert.Nil(err)
+
+	// The links are left out without forge
+	doc = newDocument("gocha", nil, []release{{Version: "1.2.0", Previous: "1.1.0", Sections: message.GetSections(ms)}})
+	assert.Empty(doc.URL)
+	assert.Empty(doc.Releases[0].CompareURL)
+	assert.Empty(doc.Releases[0].Groups[0].Scopes[0].Messages[0].URL)
+
+	var raw map[string]interface{}
+	assert.Nil(json.Unmarshal(out, &raw))
+	assert.Equal("gocha", raw["app_name"])
+	assert.Len(raw["releases"], 1)
+	assert.NotContains(string(out), `"scope": "none"`)
+	assert.NotContains(string(out), `"scope":"none"`)
+}
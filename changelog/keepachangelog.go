@@ -0,0 +1,73 @@
+package changelog
+
+import (
+	"strings"
+
+	"github.com/jgautheron/gocha/message"
+)
+
+const (
+	// securityScope sends the messages to the Security section, whatever their type
+	securityScope = "security"
+
+	// changedTitle is the section of the breaking changes of the unmapped types
+	changedTitle = "Changed"
+)
+
+// keepAChangelogSection maps commit types to a Keep a Changelog section.
+type keepAChangelogSection struct {
+	Title string
+	Types []string
+}
+
+// keepAChangelogSections are the Keep a Changelog sections, in display order.
+// The messages of the other types are left out, unless breaking: they are changes.
+var keepAChangelogSections = []keepAChangelogSection{
+	{Title: "Added", Types: []string{"feat"}},
+	{Title: changedTitle, Types: []string{"perf", "refactor", "revert"}},
+	{Title: "Deprecated", Types: []string{"deprecate", "deprecated"}},
+	{Title: "Removed", Types: []string{"remove", "removed"}},
+	{Title: "Fixed", Types: []string{"fix"}},
+	{Title: "Security", Types: []string{"security"}},
+}
+
+// getKeepAChangelogSections groups the given messages in the Keep a Changelog sections.
+func getKeepAChangelogSections(ms []message.Message) []message.Section {
+	var ss []message.Section
+
+	for _, ks := range keepAChangelogSections {
+		var sms []message.Message
+		for _, msg := range ms {
+			if getKeepAChangelogTitle(msg) == ks.Title {
+				sms = append(sms, msg)
+			}
+		}
+
+		if len(sms) != 0 {
+			ss = append(ss, message.NewSection(strings.ToLower(ks.Title), ks.Title, sms))
+		}
+	}
+
+	return ss
+}
+
+// getKeepAChangelogTitle returns the title of the section the message belongs to,
+// or an empty string if the message is left out.
+func getKeepAChangelogTitle(msg message.Message) string {
+	if msg.Scope == securityScope {
+		return "Security"
+	}
+
+	for _, ks := range keepAChangelogSections {
+		for _, tp := range ks.Types {
+			if msg.Type.String() == tp {
+				return ks.Title
+			}
+		}
+	}
+
+	if msg.Breaking {
+		return changedTitle
+	}
+	return ""
+}
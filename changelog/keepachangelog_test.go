@@ -0,0 +1,39 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/jgautheron/gocha/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeepAChangelogSections(t *testing.T) {
+	assert := assert.New(t)
+
+	ms := []message.Message{
+		{Type: "fix", Subject: "handle empty tags"},
+		{Type: "feat", Scope: "api", Subject: "add the v2 endpoints"},
+		{Type: "chore", Subject: "update the dependencies"},
+		{Type: "fix", Scope: "security", Subject: "escape the tag names"},
+		{Type: "refactor", Subject: "split the repository package"},
+		{Type: "deprecated", Subject: "the v1 endpoints"},
+		{Type: "remove", Subject: "the legacy config file"},
+		{Type: "build", Subject: "require Go 1.16", Breaking: true},
+	}
+
+	ss := getKeepAChangelogSections(ms)
+
+	var titles []string
+	for _, s := range ss {
+		titles = append(titles, s.Title)
+	}
+	assert.Equal([]string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}, titles)
+
+	assert.Equal("added", ss[0].Type)
+	assert.Equal("api", ss[0].Scopes[0].Scope)
+	assert.Len(ss[1].Scopes[0].Messages, 2)
+	assert.Equal("require Go 1.16", ss[1].Scopes[0].Messages[1].Subject)
+	assert.Equal("the legacy config file", ss[3].Scopes[0].Messages[0].Subject)
+	assert.Equal("handle empty tags", ss[4].Scopes[0].Messages[0].Subject)
+	assert.Equal("escape the tag names", ss[5].Scopes[0].Messages[0].Subject)
+}
@@ -0,0 +1,165 @@
+package changelog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jgautheron/gocha/semver"
+)
+
+const (
+	// preambleMarker can be placed in the changelog to keep
+	// the content above it at the top of the file
+	preambleMarker = "<!-- gocha:preamble -->"
+
+	// The release markers surround each release rendered by the template
+	releaseStartMarker = "<!-- gocha:release %s -->"
+	releaseEndMarker   = "<!-- /gocha:release %s -->"
+)
+
+var releaseStartRx = regexp.MustCompile(`<!-- gocha:release (\S+) -->`)
+
+// releaseBlock is a rendered release, markers included.
+type releaseBlock struct {
+	Version string
+	Content string
+}
+
+// prependToFile merges the rendered releases into the existing changelog,
+// the output is returned as is if the file does not exist yet.
+func prependToFile(path string, output []byte) ([]byte, error) {
+	existing, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return output, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(prepend(string(existing), string(output))), nil
+}
+
+// prepend inserts the rendered releases below the preamble marker of the
+// existing changelog, or at its top. The releases already present are
+// replaced in place, so that generating the same release twice is harmless,
+// and the unreleased changes are dropped once a tagged release is prepended.
+func prepend(existing, rendered string) string {
+	rbs := splitReleases(rendered)
+
+	if isTaggedOnly(rbs) {
+		if start, end, ok := findRelease(existing, unreleasedVersion); ok {
+			existing = existing[:start] + strings.TrimLeft(existing[end:], "\n")
+		}
+	}
+
+	var added []string
+	for _, rb := range rbs {
+		if start, end, ok := findRelease(existing, rb.Version); ok {
+			existing = existing[:start] + rb.Content + existing[end:]
+			continue
+		}
+		added = append(added, rb.Content)
+	}
+
+	if len(added) == 0 {
+		return existing
+	}
+
+	pos := 0
+	if idx := strings.Index(existing, preambleMarker); idx != -1 {
+		pos = idx + len(preambleMarker)
+		if pos < len(existing) && existing[pos] == '\n' {
+			pos++
+		}
+	}
+
+	return existing[:pos] + strings.Join(added, "\n\n") + "\n\n" + existing[pos:]
+}
+
+// isTaggedOnly tells whether the releases are all tagged ones,
+// as opposed to the unreleased changes or a revision range.
+func isTaggedOnly(rbs []releaseBlock) bool {
+	for _, rb := range rbs {
+		if !semver.IsValid(rb.Version) {
+			return false
+		}
+	}
+	return len(rbs) != 0
+}
+
+// splitReleases returns the releases of the rendered changelog.
+func splitReleases(rendered string) []releaseBlock {
+	var rbs []releaseBlock
+
+	for _, loc := range releaseStartRx.FindAllStringSubmatchIndex(rendered, -1) {
+		v := rendered[loc[2]:loc[3]]
+		start, end, ok := findMarkedRelease(rendered[loc[0]:], v)
+		if !ok {
+			continue
+		}
+		rbs = append(rbs, releaseBlock{
+			Version: v,
+			Content: rendered[loc[0]+start : loc[0]+end],
+		})
+	}
+
+	return rbs
+}
+
+// findRelease returns the boundaries of the given release in the changelog,
+// delimited by the release markers or, for the changelogs written by hand
+// or by previous versions, by its heading.
+func findRelease(doc, version string) (start, end int, ok bool) {
+	if start, end, ok = findMarkedRelease(doc, version); ok {
+		return start, end, ok
+	}
+	return findReleaseHeading(doc, version)
+}
+
+// findMarkedRelease returns the boundaries of the given release markers.
+func findMarkedRelease(doc, version string) (start, end int, ok bool) {
+	sm := fmt.Sprintf(releaseStartMarker, version)
+	em := fmt.Sprintf(releaseEndMarker, version)
+
+	start = strings.Index(doc, sm)
+	if start == -1 {
+		return 0, 0, false
+	}
+
+	end = strings.Index(doc[start:], em)
+	if end == -1 {
+		return 0, 0, false
+	}
+
+	return start, start + end + len(em), true
+}
+
+// findReleaseHeading returns the boundaries of the section whose first or
+// second level heading holds the given version, ex. "# app 1.2.0 (2016-03-01)"
+// or "## [1.2.0] - 2016-03-01". The section ends at the next heading of the
+// same level or above, or at the next release marker.
+func findReleaseHeading(doc, version string) (start, end int, ok bool) {
+	hrx := regexp.MustCompile(`(?m)^(#{1,2}) (?:.*\s)?\[?` + regexp.QuoteMeta(version) + `\]?(?:\s.*)?$`)
+
+	loc := hrx.FindStringSubmatchIndex(doc)
+	if loc == nil {
+		return 0, 0, false
+	}
+	start = loc[0]
+
+	lvl := loc[3] - loc[2]
+	nrx := regexp.MustCompile(fmt.Sprintf(`(?m)^(?:#{1,%d} |<!-- gocha:)`, lvl))
+
+	end = len(doc)
+	if nloc := nrx.FindStringIndex(doc[loc[1]:]); nloc != nil {
+		end = loc[1] + nloc[0]
+	}
+
+	// The blank lines before the next section are kept
+	end = start + len(strings.TrimRight(doc[start:end], "\n"))
+
+	return start, end, true
+}
@@ -0,0 +1,88 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	release100 = "<!-- gocha:release 1.0.0 -->\n# app 1.0.0\n- first\n<!-- /gocha:release 1.0.0 -->"
+	release110 = "<!-- gocha:release 1.1.0 -->\n# app 1.1.0\n- second\n<!-- /gocha:release 1.1.0 -->"
+)
+
+func TestSplitReleases(t *testing.T) {
+	assert := assert.New(t)
+
+	rbs := splitReleases("\n" + release110 + "\n\n" + release100 + "\n")
+	assert.Len(rbs, 2)
+	assert.Equal(releaseBlock{"1.1.0", release110}, rbs[0])
+	assert.Equal(releaseBlock{"1.0.0", release100}, rbs[1])
+
+	assert.Empty(splitReleases("# app 1.0.0\n- first\n"))
+}
+
+func TestPrepend(t *testing.T) {
+	assert := assert.New(t)
+
+	var prependTests = []struct {
+		existing string
+		rendered string
+		expected string
+	}{
+		// Hand-written history is kept below the new release
+		{
+			"# app 0.9.0\n- legacy\n",
+			"\n" + release100 + "\n",
+			release100 + "\n\n# app 0.9.0\n- legacy\n",
+		},
+		// The preamble stays at the top
+		{
+			"# Changelog\n" + preambleMarker + "\n" + release100 + "\n",
+			"\n" + release110 + "\n",
+			"# Changelog\n" + preambleMarker + "\n" + release110 + "\n\n" + release100 + "\n",
+		},
+		// An existing release is replaced in place
+		{
+			"# Changelog\n" + preambleMarker + "\n" + release110 + "\n\n" + release100 + "\n",
+			"\n<!-- gocha:release 1.1.0 -->\n# app 1.1.0\n- second\n- third\n<!-- /gocha:release 1.1.0 -->\n",
+			"# Changelog\n" + preambleMarker + "\n<!-- gocha:release 1.1.0 -->\n# app 1.1.0\n- second\n- third\n<!-- /gocha:release 1.1.0 -->\n\n" + release100 + "\n",
+		},
+		// A release written without markers is replaced as well
+		{
+			"# Changelog\n" + preambleMarker + "\n# app 1.1.0 (2016-03-01)\n---\n- old\n\n# app 1.0.0\n- first\n",
+			"\n" + release110 + "\n",
+			"# Changelog\n" + preambleMarker + "\n" + release110 + "\n\n# app 1.0.0\n- first\n",
+		},
+		{
+			"## [1.1.0] - 2016-03-01\n### Added\n- old\n\n## [1.0.0] - 2016-01-01\n- first\n",
+			"\n" + release110 + "\n",
+			release110 + "\n\n## [1.0.0] - 2016-01-01\n- first\n",
+		},
+		// The unreleased changes are dropped once tagged
+		{
+			preambleMarker + "\n<!-- gocha:release Unreleased -->\n# app Unreleased\n- second\n<!-- /gocha:release Unreleased -->\n\n" + release100 + "\n",
+			"\n" + release110 + "\n",
+			preambleMarker + "\n" + release110 + "\n\n" + release100 + "\n",
+		},
+		{
+			"# app Unreleased\n- second\n\n# app 1.0.0\n- first\n",
+			"\n" + release110 + "\n",
+			release110 + "\n\n# app 1.0.0\n- first\n",
+		},
+	}
+
+	for _, tt := range prependTests {
+		assert.Equal(tt.expected, prepend(tt.existing, tt.rendered))
+	}
+
+	// Prepending the same release twice is harmless
+	once := prepend("# app 0.9.0\n", release100)
+	assert.Equal(once, prepend(once, release100))
+
+	// A version is not mistaken for another one sharing its prefix
+	_, _, ok := findReleaseHeading("# app 1.1.0\n- second\n", "1.1")
+	assert.False(ok)
+	_, _, ok = findReleaseHeading("## Bug Fixes\n- 1.1.0 crash\n", "1.1.0")
+	assert.False(ok)
+}
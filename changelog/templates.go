@@ -0,0 +1,94 @@
+package changelog
+
+// markdownTemplate renders the default Markdown changelog, overridable with a template file.
+const markdownTemplate = `{% macro refs(msg) %}{% if forge %}[{{msg.ID|slice:":10"}}]({{forge.CommitURL(msg.ID)}}){% else %}{{msg.ID|slice:":10"}}{% endif %}{% for issue in msg.Issues %}, {% if issue.URL %}[{{issue.Ref}}]({{issue.URL}}){% else %}{{issue.Ref}}{% endif %}{% endfor %}{% endmacro %}{% macro message_list(scope, messages) %}{% for msg in messages %}
+{% if scope != "none" %}    {% endif %}- {{msg.Subject}} ({{refs(msg)}}){% endfor %}{% endmacro %}{% for release in releases %}
+<!-- gocha:release {{release.Version}} -->
+# {{appName}} {{release.Version}}{% if release.Codename %} "{{release.Codename}}"{% endif %} ({{release.Date|date:"2006-01-02"}})
+---
+{% if release.Breaking %}
+## Breaking Changes
+{% for msg in release.Breaking %}
+- {% if msg.Scope %}**{{msg.Scope}}:** {% endif %}{{msg.BreakingDescription}} ({{refs(msg)}}){% endfor %}
+{% endif %}{% for section in release.Sections %}
+## {{section.Title}}
+{% for group in section.Scopes %}
+{% if group.Scope != "none" %}- **{{group.Scope}}:**{% endif %}{{message_list(group.Scope, group.Messages)}}{% endfor %}
+{% endfor %}
+<!-- /gocha:release {{release.Version}} -->
+{% endfor %}`
+
+// htmlTemplate renders the HTML changelog.
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{appName}} changelog</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292e; max-width: 860px; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+h1 { border-bottom: 1px solid #eaecef; padding-bottom: .3em; }
+h1 .codename { color: #6a737d; font-weight: normal; }
+h1 time { color: #6a737d; font-size: .6em; font-weight: normal; margin-left: .5em; }
+h2 { font-size: 1.2em; margin-top: 1.5em; }
+h2.breaking { color: #cb2431; }
+ul { padding-left: 1.5em; }
+a.commit { font-family: SFMono-Regular, Consolas, monospace; font-size: .85em; color: #0366d6; text-decoration: none; }
+a.issue { color: #0366d6; }
+</style>
+</head>
+<body>
+<!-- gocha:preamble -->
+{% macro commit_link(msg) %}{% if forge %}<a class="commit" href="{{forge.CommitURL(msg.ID)}}">{{msg.ID|slice:":10"}}</a>{% else %}<code>{{msg.ID|slice:":10"}}</code>{% endif %}{% for issue in msg.Issues %}, {% if issue.URL %}<a class="issue" href="{{issue.URL}}">{{issue.Ref}}</a>{% else %}{{issue.Ref}}{% endif %}{% endfor %}{% endmacro %}{% for release in releases %}
+<!-- gocha:release {{release.Version}} -->
+<section>
+<h1>{{appName}} {{release.Version}}{% if release.Codename %} <span class="codename">"{{release.Codename}}"</span>{% endif %} <time datetime="{{release.Date|date:"2006-01-02"}}">{{release.Date|date:"2006-01-02"}}</time></h1>
+{% if release.Breaking %}<h2 class="breaking">Breaking Changes</h2>
+<ul>{% for msg in release.Breaking %}
+<li>{% if msg.Scope %}<strong>{{msg.Scope}}:</strong> {% endif %}{{msg.BreakingDescription}} ({{commit_link(msg)}})</li>{% endfor %}
+</ul>
+{% endif %}{% for section in release.Sections %}<h2>{{section.Title}}</h2>
+<ul>{% for group in section.Scopes %}{% if group.Scope != "none" %}
+<li><strong>{{group.Scope}}:</strong>
+<ul>{% for msg in group.Messages %}
+<li>{{msg.Subject}} ({{commit_link(msg)}})</li>{% endfor %}
+</ul>
+</li>{% else %}{% for msg in group.Messages %}
+<li>{{msg.Subject}} ({{commit_link(msg)}})</li>{% endfor %}{% endif %}{% endfor %}
+</ul>
+{% endfor %}</section>
+<!-- /gocha:release {{release.Version}} -->
+{% endfor %}
+</body>
+</html>
+`
+
+// textTemplate renders the plain text changelog.
+const textTemplate = `{% for release in releases %}{{appName}} {{release.Version}}{% if release.Codename %} "{{release.Codename}}"{% endif %} ({{release.Date|date:"2006-01-02"}})
+{% if release.Breaking %}
+BREAKING CHANGES
+{% for msg in release.Breaking %}
+  * {% if msg.Scope %}{{msg.Scope}}: {% endif %}{{msg.BreakingDescription}} ({{msg.ID|slice:":10"}}{% for issue in msg.Issues %}, {{issue.Ref}}{% endfor %}){% endfor %}
+{% endif %}{% for section in release.Sections %}
+{{section.Title|upper}}
+{% for group in section.Scopes %}{% for msg in group.Messages %}
+  * {% if group.Scope != "none" %}{{group.Scope}}: {% endif %}{{msg.Subject}} ({{msg.ID|slice:":10"}}{% for issue in msg.Issues %}, {{issue.Ref}}{% endfor %}){% endfor %}{% endfor %}
+{% endfor %}
+{% endfor %}
+`
+
+// keepAChangelogTemplate renders the Keep a Changelog file, see https://keepachangelog.com.
+const keepAChangelogTemplate = `{% macro refs(msg) %}{% if forge %}[{{msg.ID|slice:":10"}}]({{forge.CommitURL(msg.ID)}}){% else %}{{msg.ID|slice:":10"}}{% endif %}{% for issue in msg.Issues %}, {% if issue.URL %}[{{issue.Ref}}]({{issue.URL}}){% else %}{{issue.Ref}}{% endif %}{% endfor %}{% endmacro %}{% macro message_list(scope, messages) %}{% for msg in messages %}
+- {% if scope != "none" %}**{{scope}}:** {% endif %}{% if msg.Breaking %}**BREAKING** {% endif %}{{msg.Subject}} ({{refs(msg)}}){% endfor %}{% endmacro %}# Changelog
+
+All notable changes to {{appName}} will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+{% for release in releases %}
+{% if release.Version == unreleased %}## [Unreleased]{% else %}## [{{release.Version}}] - {{release.Date|date:"2006-01-02"}}{% endif %}
+{% for section in release.Sections %}
+### {{section.Title}}
+{% for group in section.Scopes %}{{message_list(group.Scope, group.Messages)}}{% endfor %}
+{% endfor %}{% endfor %}{% if forge %}{% for release in releases %}
+[{{release.Version}}]: {% if release.Previous %}{{forge.CompareURL(release.Previous, release.Ref)}}{% else %}{{forge.TreeURL(release.Ref)}}{% endif %}{% endfor %}
+{% endif %}`
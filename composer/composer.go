@@ -0,0 +1,129 @@
+// Package composer guides the user through writing a commit message
+// following the convention.
+package composer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/jgautheron/gocha/message"
+	"github.com/jgautheron/gocha/repository"
+)
+
+const (
+	// maxScopes is the number of suggested scopes
+	maxScopes = 10
+
+	// scopeHistory is the number of recent commits the scopes are taken from
+	scopeHistory = 500
+
+	closesToken = "Closes"
+)
+
+// Options holds the settings of the composer.
+type Options struct {
+	// DryRun prints the message instead of committing
+	DryRun bool
+}
+
+// Commit prompts for the message details, then commits the staged changes.
+func Commit(rp *repository.Repository, opts Options) {
+	// The history may be empty on the first commit
+	cmts, err := rp.GetLastCommits(scopeHistory)
+	if err != nil {
+		log.Debug(err)
+	}
+	scs := message.GetScopes(message.GetMessages(cmts))
+	if len(scs) > maxScopes {
+		scs = scs[:maxScopes]
+	}
+
+	p := newPrompter(os.Stdin, os.Stdout)
+	msg, err := compose(p, message.GetConvention().Types(), scs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	full := msg.Full()
+	if errs := message.Lint(full, message.DefaultMaxLength); len(errs) != 0 {
+		log.Fatal(errs[0])
+	}
+
+	fmt.Printf("\n%s\n\n", full)
+	if opts.DryRun {
+		return
+	}
+
+	ok, err := p.confirm("Commit")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !ok {
+		log.Info("The commit has been aborted")
+		return
+	}
+
+	id, err := rp.CreateCommit(full)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("The commit %s has been successfully created", id.String()[:10])
+}
+
+// compose asks for the message details and builds the Message.
+func compose(p *prompter, types []string, scopes []string) (*message.Message, error) {
+	tp, err := p.choose("Type", types)
+	if err != nil {
+		return nil, err
+	}
+
+	sq := "Scope (optional)"
+	if len(scopes) != 0 {
+		sq += ", previously used: " + strings.Join(scopes, ", ")
+	}
+	scope, err := p.ask(sq)
+	if err != nil {
+		return nil, err
+	}
+
+	subj, err := p.askRequired("Subject")
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := message.New(tp, scope, subj)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Body, err = p.askMultiline("Body (optional), end with an empty line")
+	if err != nil {
+		return nil, err
+	}
+
+	bc, err := p.ask("Breaking change description (optional)")
+	if err != nil {
+		return nil, err
+	}
+	if len(bc) != 0 {
+		msg.Breaking = true
+		msg.BreakingDescription = bc
+		msg.Footers = append(msg.Footers, message.Footer{Token: message.BreakingChangeToken, Value: bc})
+	}
+
+	refs, err := p.ask("Closed issues (optional), ex. #12 #34")
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range strings.FieldsFunc(refs, isRefSeparator) {
+		msg.Footers = append(msg.Footers, message.Footer{Token: closesToken, Value: ref})
+	}
+
+	return msg, nil
+}
+
+func isRefSeparator(c rune) bool {
+	return c == ' ' || c == ','
+}
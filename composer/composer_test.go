@@ -0,0 +1,42 @@
+package composer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompose(t *testing.T) {
+	assert := assert.New(t)
+
+	in := strings.Join([]string{
+		"foo", // invalid type
+		"1",   // feat
+		"cli", // scope
+		"",    // empty subject, asked again
+		"add the commit command",
+		"Guides the user through the convention.",
+		"",
+		"the --message flag has been removed",
+		"#12, #34",
+	}, "\n") + "\n"
+
+	p := newPrompter(strings.NewReader(in), &bytes.Buffer{})
+	msg, err := compose(p, []string{"feat", "fix"}, []string{"cli", "parser"})
+	assert.Nil(err)
+	assert.True(msg.Breaking)
+	assert.Equal(`feat(cli): add the commit command
+
+Guides the user through the convention.
+
+BREAKING CHANGE: the --message flag has been removed
+Closes #12
+Closes #34`, msg.Full())
+
+	// The input ends before the subject
+	p = newPrompter(strings.NewReader("fix\n\n"), &bytes.Buffer{})
+	_, err = compose(p, []string{"feat", "fix"}, nil)
+	assert.Equal(errAborted, err)
+}
@@ -0,0 +1,103 @@
+package composer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var errAborted = errors.New("The input has been closed, aborting")
+
+// prompter asks questions and reads the answers line by line.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask prints the question and returns the trimmed answer.
+func (p *prompter) ask(q string) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", q)
+	return p.readLine()
+}
+
+// askRequired asks the question until a non-empty answer is given.
+func (p *prompter) askRequired(q string) (string, error) {
+	for {
+		a, err := p.ask(q)
+		if err != nil || len(a) != 0 {
+			return a, err
+		}
+	}
+}
+
+// askMultiline reads the lines until an empty one.
+func (p *prompter) askMultiline(q string) (string, error) {
+	fmt.Fprintf(p.out, "%s:\n", q)
+
+	var ls []string
+	for {
+		l, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if len(l) == 0 {
+			return strings.Join(ls, "\n"), nil
+		}
+		ls = append(ls, l)
+	}
+}
+
+// choose lists the choices and asks until one is picked,
+// either by its number or by its name.
+func (p *prompter) choose(q string, choices []string) (string, error) {
+	for i, c := range choices {
+		fmt.Fprintf(p.out, "%3d) %s\n", i+1, c)
+	}
+
+	for {
+		a, err := p.askRequired(q)
+		if err != nil {
+			return "", err
+		}
+
+		if n, err := strconv.Atoi(a); err == nil && n > 0 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		for _, c := range choices {
+			if c == a {
+				return c, nil
+			}
+		}
+		fmt.Fprintf(p.out, "%s is not a valid choice\n", a)
+	}
+}
+
+// confirm asks a yes/no question, yes by default.
+func (p *prompter) confirm(q string) (bool, error) {
+	a, err := p.ask(q + " [Y/n]")
+	if err != nil {
+		return false, err
+	}
+	a = strings.ToLower(a)
+	return a == "" || a == "y" || a == "yes", nil
+}
+
+// readLine returns the next trimmed line, the last line may not
+// end with a newline.
+func (p *prompter) readLine() (string, error) {
+	l, err := p.in.ReadString('\n')
+	if err == io.EOF && len(l) != 0 {
+		err = nil
+	}
+	if err == io.EOF {
+		return "", errAborted
+	}
+	return strings.TrimSpace(l), err
+}
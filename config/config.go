@@ -28,6 +28,11 @@ func Get(c string) interface{} {
 	return viper.Get(c)
 }
 
+// UnmarshalKey decodes the given config key into raw.
+func UnmarshalKey(c string, raw interface{}) error {
+	return viper.UnmarshalKey(c, raw)
+}
+
 func GetCliOrConfig(c string, cli interface{}) interface{} {
 	val := cli
 	if cli == "" {
@@ -47,3 +52,42 @@ func GetCliOrConfigString(c string, cli string) string {
 	}
 	return ""
 }
+
+// GetCliOrConfigBool returns the cli flag when set, ex. "--dry-run=false"
+// overrides a true config value, and the config value otherwise.
+func GetCliOrConfigBool(c string, cli bool, set bool) bool {
+	if set {
+		return cli
+	}
+	if val, ok := GetCliOrConfig(c, "").(bool); ok {
+		return val
+	}
+	return false
+}
+
+// GetCliOrConfigStringSlice accepts comma separated values from the cli,
+// and either a string or a list from the config.
+func GetCliOrConfigStringSlice(c string, cli string) []string {
+	var vals []string
+
+	switch val := GetCliOrConfig(c, cli).(type) {
+	case string:
+		vals = strings.Split(val, ",")
+	case []interface{}:
+		for _, v := range val {
+			if s, ok := v.(string); ok {
+				vals = append(vals, s)
+			}
+		}
+	case []string:
+		vals = val
+	}
+
+	var res []string
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); len(v) != 0 {
+			res = append(res, v)
+		}
+	}
+	return res
+}
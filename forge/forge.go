@@ -0,0 +1,215 @@
+// Package forge builds the web links of the repository hosting services:
+// GitHub, GitLab, Bitbucket and Gitea, self-hosted instances included.
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Supported forges
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+	Gitea     = "gitea"
+)
+
+var (
+	errUnknownForge  = errors.New("The given forge is not supported")
+	errInvalidRemote = errors.New("The remote URL could not be parsed")
+
+	// scpRx matches the scp-like SSH syntax, ex. git@github.com:org/repo.git
+	scpRx = regexp.MustCompile(`^(?:[\w\-\.]+@)?([\w\-\.]+):([^/].*)$`)
+)
+
+// Options customizes the forge detection, mostly for self-hosted instances.
+type Options struct {
+	// Type forces the forge type, detected from the host otherwise
+	Type string
+
+	// URL forces the web URL of the repository, ex. when the
+	// SSH and web hosts differ
+	URL string
+
+	// Hosts maps the self-hosted instances to their forge type,
+	// ex. "git.example.com": "gitlab"
+	Hosts map[string]string
+}
+
+// paths holds the URL layout of a forge, relative to the repository.
+type paths struct {
+	commit, compare, issue, mergeRequest, tree string
+}
+
+var layouts = map[string]paths{
+	GitHub: {
+		commit:       "/commit/%s",
+		compare:      "/compare/%s...%s",
+		issue:        "/issues/%s",
+		mergeRequest: "/pull/%s",
+		tree:         "/tree/%s",
+	},
+	GitLab: {
+		commit:       "/-/commit/%s",
+		compare:      "/-/compare/%s...%s",
+		issue:        "/-/issues/%s",
+		mergeRequest: "/-/merge_requests/%s",
+		tree:         "/-/tree/%s",
+	},
+	Bitbucket: {
+		commit: "/commits/%s",
+		// Bitbucket expects the newest revision first
+		compare:      "/branches/compare/%[2]s%%0D%[1]s",
+		issue:        "/issues/%s",
+		mergeRequest: "/pull-requests/%s",
+		tree:         "/src/%s",
+	},
+	Gitea: {
+		commit:       "/commit/%s",
+		compare:      "/compare/%s...%s",
+		issue:        "/issues/%s",
+		mergeRequest: "/pulls/%s",
+		tree:         "/src/%s",
+	},
+}
+
+// Forge builds the links of a repository hosted on a given forge.
+type Forge struct {
+	Type string
+
+	// URL is the web URL of the repository, ex. https://github.com/jgautheron/gocha
+	URL string
+
+	paths paths
+}
+
+// New returns the forge hosting the given remote URL.
+func New(remote string, opts Options) (*Forge, error) {
+	wu := opts.URL
+	if len(wu) == 0 {
+		var err error
+		if wu, err = WebURL(remote); err != nil {
+			return nil, err
+		}
+	}
+	wu = strings.TrimSuffix(wu, "/")
+
+	tp := opts.Type
+	if len(tp) == 0 {
+		tp = detectType(wu, opts.Hosts)
+	}
+
+	p, ok := layouts[strings.ToLower(tp)]
+	if !ok {
+		return nil, errUnknownForge
+	}
+
+	return &Forge{Type: strings.ToLower(tp), URL: wu, paths: p}, nil
+}
+
+// WebURL normalises the given remote URL, SSH or HTTP(S),
+// to the web URL of the repository.
+// ex. git@gitlab.com:org/repo.git => https://gitlab.com/org/repo
+func WebURL(remote string) (string, error) {
+	remote = strings.TrimSpace(remote)
+
+	var host, path, scheme string
+	if res := scpRx.FindStringSubmatch(remote); len(res) != 0 && !strings.Contains(remote, "://") {
+		host, path = res[1], res[2]
+	} else {
+		u, err := url.Parse(remote)
+		if err != nil || len(u.Host) == 0 {
+			return "", errInvalidRemote
+		}
+
+		// The credentials are dropped, and the port unless on HTTP(S)
+		host, path = u.Hostname(), u.Path
+		if u.Scheme == "http" || u.Scheme == "https" {
+			scheme, host = u.Scheme, u.Host
+		}
+	}
+
+	if len(scheme) == 0 {
+		scheme = "https"
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	if len(path) == 0 {
+		return "", errInvalidRemote
+	}
+
+	return fmt.Sprintf("%s://%s/%s", scheme, host, path), nil
+}
+
+// knownHosts are the public instances of the forges, their subdomains match as well.
+var knownHosts = map[string]string{
+	"github.com":    GitHub,
+	"gitlab.com":    GitLab,
+	"bitbucket.org": Bitbucket,
+	"gitea.com":     Gitea,
+	"codeberg.org":  Gitea,
+}
+
+// detectType guesses the forge type from the host of the web URL,
+// the declared hosts first. GitHub is assumed for the unknown hosts.
+func detectType(wu string, hosts map[string]string) string {
+	u, err := url.Parse(wu)
+	if err != nil {
+		return GitHub
+	}
+	host := strings.ToLower(u.Hostname())
+
+	// The most specific domain wins
+	for _, hs := range []map[string]string{hosts, knownHosts} {
+		var best, btp string
+		for h, tp := range hs {
+			h = strings.ToLower(h)
+			if matchHost(host, h) && len(h) > len(best) {
+				best, btp = h, tp
+			}
+		}
+		if len(best) != 0 {
+			return btp
+		}
+	}
+
+	log.Warnf("Unknown forge for %s, assuming GitHub links: declare it in the forge hosts or use --forge", host)
+	return GitHub
+}
+
+// matchHost tells whether the host is the given domain or one of its subdomains.
+func matchHost(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// CommitURL returns the link to the given commit.
+func (f *Forge) CommitURL(id string) string {
+	return f.URL + fmt.Sprintf(f.paths.commit, id)
+}
+
+// CompareURL returns the link to the changes between both revisions.
+func (f *Forge) CompareURL(from, to string) string {
+	return f.URL + fmt.Sprintf(f.paths.compare, from, to)
+}
+
+// IssueURL returns the link to the given issue number.
+func (f *Forge) IssueURL(id string) string {
+	return f.URL + fmt.Sprintf(f.paths.issue, strings.TrimPrefix(id, "#"))
+}
+
+// MergeRequestURL returns the link to the given merge (pull) request number.
+func (f *Forge) MergeRequestURL(id string) string {
+	return f.URL + fmt.Sprintf(f.paths.mergeRequest, strings.TrimPrefix(id, "!"))
+}
+
+// TreeURL returns the link to the files at the given revision.
+func (f *Forge) TreeURL(ref string) string {
+	return f.URL + fmt.Sprintf(f.paths.tree, ref)
+}
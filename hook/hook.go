@@ -0,0 +1,93 @@
+// Package hook installs and runs the git hooks.
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/jgautheron/gocha/message"
+	"github.com/jgautheron/gocha/repository"
+)
+
+const (
+	commitMsgHook = "commit-msg"
+
+	// commitMsgScript delegates the commit-msg hook to gocha
+	commitMsgScript = `#!/bin/sh
+# Installed by gocha, checks that the commit message follows the convention.
+exec gocha hook commit-msg "$1"
+`
+)
+
+var errHookExists = errors.New("A commit-msg hook already exists, use --force to overwrite it")
+
+// Options holds the settings of the hook installation.
+type Options struct {
+	// Force overwrites the existing commit-msg hook
+	Force bool
+
+	// DryRun prints the hook path and script instead of writing it
+	DryRun bool
+}
+
+// Install writes the commit-msg hook into the repository hooks folder,
+// an existing hook is only overwritten when forced.
+func Install(rp *repository.Repository, opts Options) {
+	hp, err := rp.GetHooksPath()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hf := filepath.Join(hp, commitMsgHook)
+	if _, err = os.Stat(hf); err == nil && !opts.Force {
+		log.Fatal(errHookExists)
+	}
+
+	if opts.DryRun {
+		fmt.Printf("Hook: %s\n\n%s", hf, commitMsgScript)
+		return
+	}
+
+	if err = os.MkdirAll(hp, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(hf, []byte(commitMsgScript), 0755)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("%s has been successfully installed", hf)
+}
+
+// CommitMsg validates the message file given by git to the commit-msg hook,
+// reports the problems and tells whether the message is valid.
+// The comment lines are ignored, as well as the merge and fixup commits.
+func CommitMsg(file string, maxLength int) bool {
+	dat, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	msg := message.Clean(string(dat))
+
+	// Empty messages are refused by git anyway
+	if len(msg) == 0 || message.IsExempt(msg) {
+		return true
+	}
+
+	errs := message.Lint(msg, maxLength)
+	if len(errs) == 0 {
+		return true
+	}
+
+	fmt.Fprintln(os.Stderr, "The commit message does not follow the convention:")
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "    - %s\n", err)
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+// Package linter checks that the commit messages follow the convention.
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/jgautheron/gocha/message"
+	"github.com/jgautheron/gocha/repository"
+)
+
+// Options holds the settings of the linter.
+type Options struct {
+	// MaxLength is the maximum length of the subject line, 0 disables the check
+	MaxLength int
+}
+
+// Lint checks the commits of the given revision range, defaults to the
+// commits since the last tag, reports the invalid ones and returns their count.
+func Lint(rp *repository.Repository, rng string, opts Options) int {
+	cmts, err := getCommitList(rp, rng)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var cnt int
+	for _, co := range cmts {
+		if message.IsExempt(co.Description) {
+			continue
+		}
+
+		errs := message.Lint(co.Description, opts.MaxLength)
+		if len(errs) == 0 {
+			continue
+		}
+
+		cnt++
+		fmt.Printf("%s %s\n", co.ID.String()[:10], strings.SplitN(co.Description, "\n", 2)[0])
+		for _, err := range errs {
+			fmt.Printf("    - %s\n", err)
+		}
+	}
+
+	log.Infof("%d commits checked, %d not following the convention", len(cmts), cnt)
+	return cnt
+}
+
+// getCommitList returns the commits of the given range, or the ones
+// since the last tag. Without any tag, the whole history is checked.
+func getCommitList(rp *repository.Repository, rng string) ([]repository.Commit, error) {
+	if len(rng) != 0 {
+		return getRangeCommits(rp, rng)
+	}
+
+	lt, err := rp.GetLastTag()
+	if repository.IsNoTagFound(err) {
+		log.Debug(err)
+		return rp.GetCommitListForHead()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rp.GetCommitListSinceTag(lt)
+}
+
+// getRangeCommits returns the commits of the given range, as git log does:
+// "A..B" the commits of B not in A, "A...B" the ones of either side but
+// not both, and a single revision its whole history. An omitted side is HEAD.
+func getRangeCommits(rp *repository.Repository, rng string) ([]repository.Commit, error) {
+	if rv := strings.SplitN(rng, "...", 2); len(rv) == 2 {
+		from, to := orHead(rv[0]), orHead(rv[1])
+		cmts, err := rp.GetCommitsBetween(from, to)
+		if err != nil {
+			return nil, err
+		}
+		rcmts, err := rp.GetCommitsBetween(to, from)
+		if err != nil {
+			return nil, err
+		}
+		return append(cmts, rcmts...), nil
+	}
+
+	if rv := strings.SplitN(rng, "..", 2); len(rv) == 2 {
+		return rp.GetCommitsBetween(orHead(rv[0]), orHead(rv[1]))
+	}
+
+	return rp.GetCommitsBetween("", rng)
+}
+
+// orHead returns the given revision, HEAD if empty.
+func orHead(rev string) string {
+	if len(rev) == 0 {
+		return "HEAD"
+	}
+	return rev
+}
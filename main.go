@@ -10,8 +10,13 @@ import (
 	"github.com/codegangsta/cli"
 	"github.com/jgautheron/gocha/bumper"
 	"github.com/jgautheron/gocha/changelog"
+	"github.com/jgautheron/gocha/composer"
 	"github.com/jgautheron/gocha/config"
+	"github.com/jgautheron/gocha/forge"
+	"github.com/jgautheron/gocha/hook"
+	"github.com/jgautheron/gocha/linter"
 	"github.com/jgautheron/gocha/logger"
+	"github.com/jgautheron/gocha/message"
 	"github.com/jgautheron/gocha/repository"
 )
 
@@ -23,33 +28,95 @@ import (
 const (
 	argLogLevel = "log-level"
 	argRepoPath = "repo-path"
+	argDryRun   = "dry-run"
+
+	// Commit message convention
+	argConvention = "convention"
+	cfgTypes      = "types"
+	cfgIssues     = "issues"
+
+	// Tags settings
+	argReachableOnly = "reachable-only"
 
 	// Git Signature
 	argUserName  = "username"
 	argUserEmail = "email"
 
 	// Push settings
+	argRemote         = "remote"
 	argPushStrategy   = "push-strategy"
 	argPushUsername   = "push-username"
 	argPushPublicKey  = "push-public-key"
 	argPushPrivateKey = "push-private-key"
 	argPushPassphrase = "push-passphrase"
+	argPushToken      = "push-token"
+	argPushPassword   = "push-password"
+
+	// Certificate checks
+	argKnownHosts         = "known-hosts"
+	argInsecureSkipVerify = "insecure-skip-verify"
 
 	// Changelog settings
 	argAppName    = "app-name"
 	argAppTag     = "tag"
 	argOutputFile = "output"
+	argUnreleased = "unreleased"
+	argFrom       = "from"
+	argTo         = "to"
+	argInitial    = "initial"
+	argAll        = "all"
+	argPrepend    = "prepend"
+	argTemplate   = "template"
+	argFormat     = "format"
+	argForge      = "forge"
+	argForgeURL   = "forge-url"
+	cfgForgeHosts = "forge.hosts"
+
+	// Bump settings
+	argPreReleaseID = "id"
+	argNoPush       = "no-push"
+
+	// Lint settings
+	argMaxLength = "max-length"
+
+	// Hook settings
+	argForce = "force"
 
 	// Commands
 	cmdBump              = "bump"
 	cmdBumpMajor         = "major"
 	cmdBumpMinor         = "minor"
 	cmdBumpPatch         = "patch"
+	cmdBumpAuto          = "auto"
+	cmdBumpPreMajor      = "premajor"
+	cmdBumpPreMinor      = "preminor"
+	cmdBumpPrePatch      = "prepatch"
+	cmdBumpPreRelease    = "prerelease"
+	cmdBumpRelease       = "release"
+	cmdPush              = "push"
+	cmdLint              = "lint"
+	cmdHook              = "hook"
+	cmdHookInstall       = "install"
+	cmdHookCommitMsg     = "commit-msg"
+	cmdCommit            = "commit"
 	cmdChangelog         = "changelog"
 	cmdChangelogGenerate = "generate"
 )
 
 var (
+	noPushFlag = cli.BoolFlag{
+		Name:   argNoPush,
+		EnvVar: "NO_PUSH",
+		Usage:  "only create the tag locally, push it later with the push command",
+	}
+
+	maxLengthFlag = cli.IntFlag{
+		Name:   argMaxLength,
+		Value:  message.DefaultMaxLength,
+		EnvVar: "MAX_LENGTH",
+		Usage:  "maximum length of the subject line, 0 to disable",
+	}
+
 	// Build vars
 	// Do not set these manually! these variables
 	// are meant to be set through ldflags
@@ -82,6 +149,21 @@ func main() {
 			EnvVar: "REPO_PATH",
 			Usage:  "path to the repository",
 		},
+		cli.BoolFlag{
+			Name:   argDryRun,
+			EnvVar: "DRY_RUN",
+			Usage:  "print the release details without touching the repository, the remote or the filesystem",
+		},
+		cli.StringFlag{
+			Name:   argConvention,
+			EnvVar: "CONVENTION",
+			Usage:  "commit message convention: angular or conventional [angular]",
+		},
+		cli.BoolFlag{
+			Name:   argReachableOnly,
+			EnvVar: "REACHABLE_ONLY",
+			Usage:  "only consider the tags reachable from HEAD",
+		},
 
 		// Git Signature
 		cli.StringFlag{
@@ -96,10 +178,15 @@ func main() {
 		},
 
 		// Push settings
+		cli.StringFlag{
+			Name:   argRemote,
+			EnvVar: "REMOTE",
+			Usage:  "comma separated remotes to push to, the first one is used for the links [origin]",
+		},
 		cli.StringFlag{
 			Name:   argPushStrategy,
 			EnvVar: "PUSH_STRATEGY",
-			Usage:  "push strategy: ssh-agent, ssh-key",
+			Usage:  "push strategy: ssh-agent, ssh-key, https-token, userpass or none",
 		},
 		cli.StringFlag{
 			Name:   argPushUsername,
@@ -123,6 +210,28 @@ func main() {
 			EnvVar: "PUSH_PASSPHRASE",
 			Usage:  "passphrase for the private key",
 		},
+		cli.StringFlag{
+			Name:   argPushToken,
+			EnvVar: "PUSH_TOKEN",
+			Usage:  "token for the https-token strategy",
+		},
+		cli.StringFlag{
+			Name:   argPushPassword,
+			EnvVar: "PUSH_PASSWORD",
+			Usage:  "password for the userpass strategy",
+		},
+
+		// Certificate checks
+		cli.StringFlag{
+			Name:   argKnownHosts,
+			EnvVar: "KNOWN_HOSTS",
+			Usage:  "path to the known hosts file used to verify the SSH host keys [~/.ssh/known_hosts]",
+		},
+		cli.BoolFlag{
+			Name:   argInsecureSkipVerify,
+			EnvVar: "INSECURE_SKIP_VERIFY",
+			Usage:  "skip the SSH host key and HTTPS certificate verification, insecure",
+		},
 	}
 
 	app.Commands = []cli.Command{{
@@ -132,6 +241,7 @@ func main() {
 			{
 				Name:  cmdBumpMajor,
 				Usage: "major version bump",
+				Flags: []cli.Flag{noPushFlag},
 				Action: func(c *cli.Context) {
 					initBump(c, cmdBumpMajor)
 				},
@@ -139,6 +249,7 @@ func main() {
 			{
 				Name:  cmdBumpMinor,
 				Usage: "minor version bump",
+				Flags: []cli.Flag{noPushFlag},
 				Action: func(c *cli.Context) {
 					initBump(c, cmdBumpMinor)
 				},
@@ -146,11 +257,95 @@ func main() {
 			{
 				Name:  cmdBumpPatch,
 				Usage: "patch version bump",
+				Flags: []cli.Flag{noPushFlag},
 				Action: func(c *cli.Context) {
 					initBump(c, cmdBumpPatch)
 				},
 			},
+			{
+				Name:  cmdBumpAuto,
+				Usage: "infer the bump from the commit messages since the last tag",
+				Flags: []cli.Flag{noPushFlag},
+				Action: func(c *cli.Context) {
+					initBump(c, cmdBumpAuto)
+				},
+			},
+			{
+				Name:  cmdBumpPreMajor,
+				Usage: "major pre-release bump, ex. 2.0.0-rc.1",
+				Flags: []cli.Flag{preReleaseIDFlag("rc"), noPushFlag},
+				Action: func(c *cli.Context) {
+					initBump(c, cmdBumpPreMajor)
+				},
+			},
+			{
+				Name:  cmdBumpPreMinor,
+				Usage: "minor pre-release bump, ex. 1.3.0-rc.1",
+				Flags: []cli.Flag{preReleaseIDFlag("rc"), noPushFlag},
+				Action: func(c *cli.Context) {
+					initBump(c, cmdBumpPreMinor)
+				},
+			},
+			{
+				Name:  cmdBumpPrePatch,
+				Usage: "patch pre-release bump, ex. 1.2.4-rc.1",
+				Flags: []cli.Flag{preReleaseIDFlag("rc"), noPushFlag},
+				Action: func(c *cli.Context) {
+					initBump(c, cmdBumpPrePatch)
+				},
+			},
+			{
+				Name:  cmdBumpPreRelease,
+				Usage: "pre-release bump, ex. 2.0.0-rc.1 => 2.0.0-rc.2",
+				Flags: []cli.Flag{preReleaseIDFlag(""), noPushFlag},
+				Action: func(c *cli.Context) {
+					initBump(c, cmdBumpPreRelease)
+				},
+			},
+			{
+				Name:  cmdBumpRelease,
+				Usage: "release the current pre-release, ex. 2.0.0-rc.2 => 2.0.0",
+				Flags: []cli.Flag{noPushFlag},
+				Action: func(c *cli.Context) {
+					initBump(c, cmdBumpRelease)
+				},
+			},
+		},
+	}, {
+		Name:   cmdPush,
+		Usage:  "push [tag], or the last release tag if omitted",
+		Action: initPush,
+	}, {
+		Name:   cmdLint,
+		Usage:  "lint [rev-range], check the commit messages, since the last tag if omitted",
+		Action: initLint,
+		Flags:  []cli.Flag{maxLengthFlag},
+	}, {
+		Name:  cmdHook,
+		Usage: "manage the git hooks",
+		Subcommands: []cli.Command{
+			{
+				Name:   cmdHookInstall,
+				Usage:  "install the commit-msg hook in the repository",
+				Action: initHookInstall,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  argForce,
+						Usage: "overwrite the existing commit-msg hook",
+					},
+				},
+			},
+			{
+				Name:   cmdHookCommitMsg,
+				Usage:  "commit-msg <file>, check the commit message file, called by git",
+				Action: initHookCommitMsg,
+				Flags:  []cli.Flag{maxLengthFlag},
+			},
 		},
+	}, {
+		Name:   cmdCommit,
+		Usage:  "compose a commit message following the convention, then commit the staged changes",
+		Action: initCommit,
 	}, {
 		Name:  cmdChangelog,
 		Usage: "manipulate the changelog",
@@ -175,6 +370,47 @@ func main() {
 						EnvVar: "OUTPUT_FILE",
 						Usage:  "output file path",
 					},
+					cli.BoolFlag{
+						Name:  argUnreleased,
+						Usage: "generate the changelog of the commits made since the last tag",
+					},
+					cli.StringFlag{
+						Name:  argFrom,
+						Usage: "generate the changelog from the given revision (tag, branch or SHA), excluded",
+					},
+					cli.StringFlag{
+						Name:  argTo,
+						Usage: "generate the changelog up to the given revision (tag, branch or SHA), HEAD by default",
+					},
+					cli.BoolFlag{
+						Name:  argInitial,
+						Usage: "include all the commits from the root of the history up to the tag",
+					},
+					cli.BoolFlag{
+						Name:  argAll,
+						Usage: "generate the changelog of every release, newest first",
+					},
+					cli.BoolFlag{
+						Name:  argPrepend,
+						Usage: "insert the release at the top of the existing changelog instead of overwriting it",
+					},
+					cli.StringFlag{
+						Name:  argTemplate,
+						Usage: "path of the pongo2 template to render, overrides the .gocha/changelog.tpl file of the repository",
+					},
+					cli.StringFlag{
+						Name:  argFormat,
+						Value: changelog.FormatMarkdown,
+						Usage: "output format: markdown, keepachangelog, html, text, json or yaml",
+					},
+					cli.StringFlag{
+						Name:  argForge,
+						Usage: "forge hosting the repository: github, gitlab, bitbucket or gitea, detected from the remote URL by default",
+					},
+					cli.StringFlag{
+						Name:  argForgeURL,
+						Usage: "web URL of the repository, when it can't be derived from the remote URL",
+					},
 				},
 			},
 		},
@@ -185,17 +421,14 @@ func main() {
 }
 
 func initialize(c *cli.Context) *repository.Repository {
-	// Configure logging
-	loglvl := config.GetCliOrConfigString(argLogLevel, c.GlobalString(argLogLevel))
-	if len(loglvl) == 0 {
-		loglvl = log.InfoLevel.String()
-	}
-	logger.SetLogLevel(loglvl)
+	configure(c)
 
 	rp, err := repository.New(c.GlobalString(argRepoPath))
 	if err != nil {
 		log.Fatal(err)
 	}
+	rp.SetRemotes(config.GetCliOrConfigStringSlice(argRemote, c.GlobalString(argRemote))...)
+	rp.SetReachableTagsOnly(getCliOrConfigBool(c, argReachableOnly, argReachableOnly))
 
 	var user *repository.User
 	var push *repository.Push
@@ -203,7 +436,7 @@ func initialize(c *cli.Context) *repository.Repository {
 	// Get the user name & email for git signatures
 	un := config.GetCliOrConfigString(argUserName, c.GlobalString(argUserName))
 	ue := config.GetCliOrConfigString(argUserEmail, c.GlobalString(argUserEmail))
-	if len(un) == 0 || len(ue) == 0 {
+	if len(un) != 0 && len(ue) != 0 {
 		user = &repository.User{
 			Name:  un,
 			Email: ue,
@@ -224,21 +457,125 @@ func initialize(c *cli.Context) *repository.Repository {
 		PublicKey:  config.GetCliOrConfigString("push/public-key", c.GlobalString(argPushPublicKey)),
 		PrivateKey: config.GetCliOrConfigString("push/private-key", c.GlobalString(argPushPrivateKey)),
 		Passphrase: config.GetCliOrConfigString("push/passphrase", c.GlobalString(argPushPassphrase)),
+		Token:      config.GetCliOrConfigString("push/token", c.GlobalString(argPushToken)),
+		Password:   config.GetCliOrConfigString("push/password", c.GlobalString(argPushPassword)),
+		KnownHosts: config.GetCliOrConfigString("push/known-hosts", c.GlobalString(argKnownHosts)),
+
+		InsecureSkipVerify: getCliOrConfigBool(c, "push/insecure-skip-verify", argInsecureSkipVerify),
 	}
 
 	creds := &repository.Credentials{
 		User: user,
 		Push: push,
 	}
-	rp.SetCredentials(creds)
+	if err := rp.SetCredentials(creds); err != nil {
+		log.Fatal(err)
+	}
 
 	return rp
 }
 
+// configure sets up the logging and the commit message convention,
+// for the commands not requiring the repository.
+func configure(c *cli.Context) {
+	// Configure logging
+	loglvl := config.GetCliOrConfigString(argLogLevel, c.GlobalString(argLogLevel))
+	if len(loglvl) == 0 {
+		loglvl = log.InfoLevel.String()
+	}
+	logger.SetLogLevel(loglvl)
+
+	// Select the commit message convention
+	cv := config.GetCliOrConfigString(argConvention, c.GlobalString(argConvention))
+	if len(cv) != 0 {
+		if err := message.SetConvention(cv); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Declared commit types, only available in the config file
+	var tcs []message.TypeConfig
+	if err := config.UnmarshalKey(cfgTypes, &tcs); err != nil {
+		log.Fatal(err)
+	}
+	message.SetTypes(tcs)
+
+	// Issue reference patterns, only available in the config file
+	var ips []message.IssuePattern
+	if err := config.UnmarshalKey(cfgIssues, &ips); err != nil {
+		log.Fatal(err)
+	}
+	if err := message.SetIssuePatterns(ips); err != nil {
+		log.Fatal(err)
+	}
+}
+
 // initialize wraps the processor call and directly passes cli values.
 func initBump(c *cli.Context, bmp string) {
 	rp := initialize(c)
-	bumper.Up(rp, bmp)
+	bumper.Up(rp, bmp, bumper.Options{
+		PreReleaseID: c.String(argPreReleaseID),
+		DryRun:       isDryRun(c),
+		NoPush:       c.Bool(argNoPush),
+		Changelog: changelog.Options{
+			AppName:      getAppName(c),
+			TemplateFile: config.GetCliOrConfigString("changelog/template", ""),
+			Forge:        getForgeOptions(c),
+		},
+	})
+}
+
+func initPush(c *cli.Context) {
+	rp := initialize(c)
+	bumper.Push(rp, c.Args().First(), bumper.Options{
+		DryRun: isDryRun(c),
+	})
+}
+
+// preReleaseIDFlag returns the pre-release identifier flag
+// shared by the pre-release bumps.
+func preReleaseIDFlag(def string) cli.Flag {
+	return cli.StringFlag{
+		Name:   argPreReleaseID,
+		Value:  def,
+		EnvVar: "PRERELEASE_ID",
+		Usage:  "pre-release identifier, ex. alpha, beta, rc",
+	}
+}
+
+func initLint(c *cli.Context) {
+	rp := initialize(c)
+	cnt := linter.Lint(rp, c.Args().First(), linter.Options{
+		MaxLength: c.Int(argMaxLength),
+	})
+	if cnt != 0 {
+		os.Exit(1)
+	}
+}
+
+func initHookInstall(c *cli.Context) {
+	rp := initialize(c)
+	hook.Install(rp, hook.Options{
+		Force:  c.Bool(argForce),
+		DryRun: isDryRun(c),
+	})
+}
+
+func initHookCommitMsg(c *cli.Context) {
+	configure(c)
+	if len(c.Args().First()) == 0 {
+		log.Fatal("The commit message file is required")
+	}
+	if !hook.CommitMsg(c.Args().First(), c.Int(argMaxLength)) {
+		os.Exit(1)
+	}
+}
+
+func initCommit(c *cli.Context) {
+	rp := initialize(c)
+	composer.Commit(rp, composer.Options{
+		DryRun: isDryRun(c),
+	})
 }
 
 func getAppName(c *cli.Context) string {
@@ -264,5 +601,48 @@ func initGenerate(c *cli.Context) {
 	}
 
 	rp := initialize(c)
-	changelog.Generate(rp, c.String(argAppTag), getAppName(c), outputFile)
+	changelog.Generate(rp, changelog.Options{
+		AppName:      getAppName(c),
+		Tag:          c.String(argAppTag),
+		OutputFile:   outputFile,
+		DryRun:       isDryRun(c),
+		Unreleased:   c.Bool(argUnreleased),
+		From:         c.String(argFrom),
+		To:           c.String(argTo),
+		Initial:      c.Bool(argInitial),
+		All:          c.Bool(argAll),
+		Prepend:      c.Bool(argPrepend),
+		TemplateFile: config.GetCliOrConfigString("changelog/template", c.String(argTemplate)),
+		Format:       c.String(argFormat),
+		Forge:        getForgeOptions(c),
+	})
+}
+
+// isDryRun tells whether the dry run is enabled, from the cli or the config.
+func isDryRun(c *cli.Context) bool {
+	return getCliOrConfigBool(c, argDryRun, argDryRun)
+}
+
+// getCliOrConfigBool returns the given global flag if set, the config value otherwise.
+func getCliOrConfigBool(c *cli.Context, cfg, name string) bool {
+	return config.GetCliOrConfigBool(cfg, c.GlobalBool(name), c.GlobalIsSet(name))
+}
+
+// getForgeOptions returns the forge settings, the flags are only
+// available on the changelog generation.
+func getForgeOptions(c *cli.Context) forge.Options {
+	return forge.Options{
+		Type:  config.GetCliOrConfigString("forge/type", c.String(argForge)),
+		URL:   config.GetCliOrConfigString("forge/url", c.String(argForgeURL)),
+		Hosts: getForgeHosts(),
+	}
+}
+
+// getForgeHosts returns the self-hosted forges declared in the config.
+func getForgeHosts() map[string]string {
+	var hosts map[string]string
+	if err := config.UnmarshalKey(cfgForgeHosts, &hosts); err != nil {
+		log.Fatal(err)
+	}
+	return hosts
 }
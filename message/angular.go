@@ -0,0 +1,52 @@
+package message
+
+import "regexp"
+
+const angularExpr = `(?si)^([a-z]{3,})(?:\(([\w\d\-_$]+)\))?(!)?:([^\r\n]+)(.+)?`
+
+var angularRx = regexp.MustCompile(angularExpr)
+
+// angular implements the AngularJS convention, restricted to its seven types.
+type angular struct{}
+
+// Parse deconstructs the given AngularJS commit message.
+func (a *angular) Parse(msg string) (*Message, error) {
+	// Initally wanted to use fmt.Sscanf to split the string
+	// but it didn't work as expected: scanning requires items
+	// to be space-separated
+	res := angularRx.FindStringSubmatch(msg)
+	if len(res) != 6 {
+		return nil, errNoMatch
+	}
+
+	if !a.IsValidType(res[1]) {
+		return nil, errInvalidType
+	}
+
+	return newMessage(res[1], res[2], res[4], len(res[3]) != 0, res[5]), nil
+}
+
+// IsValidType tells whether the type is one of the AngularJS ones,
+// or one of the declared types.
+func (a *angular) IsValidType(tp string) bool {
+	if declared, ok := isDeclaredType(tp); ok {
+		return declared
+	}
+
+	switch messageType(tp) {
+	case Chore, Test, Docs, Feat, Fix, Refactor, Style:
+		return true
+	}
+	return false
+}
+
+// Types returns the AngularJS types, or the declared ones.
+func (a *angular) Types() []string {
+	var tps []string
+	for _, tc := range getTypeConfigs() {
+		if a.IsValidType(tc.Type) {
+			tps = append(tps, tc.Type)
+		}
+	}
+	return tps
+}
@@ -0,0 +1,68 @@
+package message
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	AngularConvention      = "angular"
+	ConventionalConvention = "conventional"
+)
+
+var errUnknownConvention = errors.New("The given convention is not supported")
+
+// Convention deconstructs the commit messages following a given style.
+type Convention interface {
+	// Parse creates a Message out of the given commit message
+	Parse(msg string) (*Message, error)
+
+	// IsValidType tells whether the given type is accepted
+	IsValidType(tp string) bool
+
+	// Types lists the suggested types
+	Types() []string
+}
+
+// conventions lists the available conventions by name.
+var conventions = map[string]Convention{
+	AngularConvention:      &angular{},
+	ConventionalConvention: &conventional{},
+}
+
+// current is the convention used for parsing, AngularJS by default.
+var current = conventions[AngularConvention]
+
+// SetConvention selects the convention used for parsing the messages.
+func SetConvention(name string) error {
+	cv, ok := conventions[strings.ToLower(name)]
+	if !ok {
+		return errUnknownConvention
+	}
+	current = cv
+	return nil
+}
+
+// GetConvention returns the convention used for parsing the messages.
+func GetConvention() Convention {
+	return current
+}
+
+// newMessage builds a Message from the deconstructed header
+// and the rest of the commit message.
+func newMessage(tp, scope, subj string, breaking bool, rest string) *Message {
+	body, footers := splitFooters(rest)
+
+	m := &Message{
+		Type:     messageType(tp),
+		Scope:    scope,
+		Subject:  strings.TrimSpace(subj),
+		Body:     body,
+		Footers:  footers,
+		Breaking: breaking,
+	}
+	m.setBreakingChange()
+	m.Issues = findIssues(m.Subject, rest)
+
+	return m
+}
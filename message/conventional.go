@@ -0,0 +1,49 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+)
+
+const conventionalExpr = `(?s)^([a-zA-Z][\w\-]*)(?:\(([^()\r\n]+)\))?(!)?: ([^\r\n]+)(.*)`
+
+var (
+	conventionalRx     = regexp.MustCompile(conventionalExpr)
+	conventionalTypeRx = regexp.MustCompile(`^[a-zA-Z][\w\-]*$`)
+)
+
+// conventional implements the Conventional Commits 1.0 convention,
+// any type is accepted and normalised to lower case.
+type conventional struct{}
+
+// Parse deconstructs the given Conventional Commits message.
+func (c *conventional) Parse(msg string) (*Message, error) {
+	res := conventionalRx.FindStringSubmatch(msg)
+	if len(res) != 6 {
+		return nil, errNoMatch
+	}
+
+	tp := strings.ToLower(res[1])
+	if !c.IsValidType(tp) {
+		return nil, errInvalidType
+	}
+
+	return newMessage(tp, strings.TrimSpace(res[2]), res[4], len(res[3]) != 0, res[5]), nil
+}
+
+// IsValidType accepts any noun, unless the types have been declared.
+func (c *conventional) IsValidType(tp string) bool {
+	if declared, ok := isDeclaredType(tp); ok {
+		return declared
+	}
+	return conventionalTypeRx.MatchString(tp)
+}
+
+// Types returns the declared types, or the usual ones.
+func (c *conventional) Types() []string {
+	var tps []string
+	for _, tc := range getTypeConfigs() {
+		tps = append(tps, tc.Type)
+	}
+	return tps
+}
@@ -0,0 +1,108 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// footerExpr matches the first line of a footer, ex. "Closes #12" or "Refs: 42"
+	footerExpr = `^(BREAKING[ \-]CHANGE|[\w\-]+)(?:(: )|( #))(.*)$`
+
+	// BreakingChangeToken is the footer token describing a breaking change
+	BreakingChangeToken    = "BREAKING CHANGE"
+	breakingChangeAltToken = "BREAKING-CHANGE"
+)
+
+var footerRx = regexp.MustCompile(footerExpr)
+
+// Footer is a trailer of the message, ex. "BREAKING CHANGE: ...", "Closes #12".
+type Footer struct {
+	Token, Value string
+
+	// Separator is written between the token and the value, ": " or " ",
+	// as parsed. When empty, the references are written "Closes #12".
+	Separator string
+}
+
+// IsBreaking tells whether the footer describes a breaking change.
+func (f Footer) IsBreaking() bool {
+	return f.Token == BreakingChangeToken || f.Token == breakingChangeAltToken
+}
+
+// String returns the footer as written in the message.
+func (f Footer) String() string {
+	if len(f.Separator) != 0 {
+		return f.Token + f.Separator + f.Value
+	}
+	if !f.IsBreaking() && strings.HasPrefix(f.Value, "#") {
+		return f.Token + " " + f.Value
+	}
+	return f.Token + ": " + f.Value
+}
+
+// splitFooters separates the body from the footers, the last paragraph
+// if all its lines start with a footer token. Only the breaking change
+// description may continue on the following lines.
+func splitFooters(rest string) (string, []Footer) {
+	rest = strings.TrimSpace(strings.Replace(rest, "\r\n", "\n", -1))
+
+	idx := strings.LastIndex(rest, "\n\n")
+	if !isTrailerBlock(strings.TrimLeft(rest[idx+1:], "\n")) {
+		return rest, nil
+	}
+	if idx < 0 {
+		return "", parseFooters(rest)
+	}
+
+	return strings.TrimSpace(rest[:idx]), parseFooters(rest[idx+2:])
+}
+
+// isTrailerBlock tells whether the given paragraph only holds footers.
+func isTrailerBlock(p string) bool {
+	if len(p) == 0 {
+		return false
+	}
+
+	var breaking bool
+	for _, l := range strings.Split(p, "\n") {
+		res := footerRx.FindStringSubmatch(l)
+		if len(res) == 0 {
+			if !breaking {
+				return false
+			}
+			continue
+		}
+		breaking = Footer{Token: res[1]}.IsBreaking()
+	}
+
+	return true
+}
+
+// parseFooters creates the footers out of the given lines.
+func parseFooters(s string) []Footer {
+	var fs []Footer
+
+	for _, l := range strings.Split(s, "\n") {
+		res := footerRx.FindStringSubmatch(l)
+		if len(res) == 0 {
+			if len(fs) != 0 {
+				fs[len(fs)-1].Value += "\n" + l
+			}
+			continue
+		}
+
+		val, sep := res[4], res[2]
+		if len(res[3]) != 0 {
+			// Keep the hash for references, ex. "Closes #12"
+			val, sep = "#"+val, " "
+		}
+		fs = append(fs, Footer{Token: res[1], Value: val, Separator: sep})
+	}
+
+	for idx := range fs {
+		fs[idx].Value = strings.TrimSpace(fs[idx].Value)
+	}
+
+	return fs
+}
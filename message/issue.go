@@ -0,0 +1,218 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in issue trackers
+const (
+	GitHubIssues  = "github"
+	JiraIssues    = "jira"
+	MergeRequests = "merge-requests"
+)
+
+// issueIDPlaceholder is replaced by the issue ID in the URL templates.
+const issueIDPlaceholder = "{id}"
+
+// jiraPattern matches the issues of the given project keys, ex. "JIRA-456".
+const jiraPattern = `\b(?P<id>(?:%s)-\d+)\b`
+
+var (
+	errUnknownTracker = errors.New("The given issue tracker is not supported")
+	errNoIssuePattern = errors.New("A pattern is required for the custom issue trackers")
+	errNoIssueURL     = errors.New("An URL is required for the Jira and custom issue trackers")
+	errNoJiraKeys     = errors.New("The Jira project keys are required")
+)
+
+// forgePatterns are the patterns of the built-in trackers linked to the forge.
+var forgePatterns = map[string]string{
+	GitHubIssues:  `(?:^|[^\w&/])(?P<ref>#(?P<id>\d+))\b`,
+	MergeRequests: `(?:^|[^\w&/!])(?P<ref>(?:!|\b(?:PR|MR) ?#)(?P<id>\d+))\b`,
+}
+
+// defaultIssuePatterns link the merge requests, ex. "!12" or "PR #12",
+// then the GitHub issues, ex. "#12".
+var defaultIssuePatterns = []IssuePattern{{Tracker: MergeRequests}, {Tracker: GitHubIssues}}
+
+// IssuePattern describes how the issue references are found in the messages.
+type IssuePattern struct {
+	// Tracker is a built-in tracker, github, jira or merge-requests, setting the pattern
+	Tracker string
+
+	// Keys are the Jira project keys, ex. "JIRA" for "JIRA-456"
+	Keys []string
+
+	// Pattern is a custom regular expression. The issue ID is its group
+	// named "id", or its first group. The reference, as displayed, is
+	// its group named "ref", or the whole match.
+	Pattern string
+
+	// URL is the link to the issue, "{id}" is replaced by the issue ID.
+	// Only the github and merge-requests trackers can leave it empty,
+	// to link to the forge.
+	URL string
+
+	// MergeRequest is set for the merge (pull) request references
+	MergeRequest bool
+
+	rx *regexp.Regexp
+}
+
+// Issue is a reference to an issue found in a message, ex. "Closes #12".
+type Issue struct {
+	// Ref is the reference as written, ex. "#12" or "JIRA-456"
+	Ref string
+
+	// ID is the issue identifier, ex. "12" or "JIRA-456"
+	ID string
+
+	// URL is the link to the issue, empty if unknown
+	URL string
+
+	// MergeRequest is set for the merge (pull) request references
+	MergeRequest bool
+}
+
+// issuePatterns are the patterns used for finding the issue references,
+// the GitHub ones by default.
+var issuePatterns = mustCompileIssuePatterns(defaultIssuePatterns)
+
+// SetIssuePatterns declares how the issue references are found and linked.
+// An empty list restores the defaults, merge requests and GitHub references.
+func SetIssuePatterns(ips []IssuePattern) error {
+	if len(ips) == 0 {
+		ips = defaultIssuePatterns
+	}
+
+	cps, err := compileIssuePatterns(ips)
+	if err != nil {
+		return err
+	}
+	issuePatterns = cps
+	return nil
+}
+
+func compileIssuePatterns(ips []IssuePattern) ([]IssuePattern, error) {
+	var cps []IssuePattern
+
+	for _, ip := range ips {
+		tr := strings.ToLower(ip.Tracker)
+
+		switch {
+		case len(ip.Pattern) != 0:
+			if len(ip.URL) == 0 {
+				return nil, errNoIssueURL
+			}
+		case tr == JiraIssues:
+			if len(ip.Keys) == 0 {
+				return nil, errNoJiraKeys
+			}
+			if len(ip.URL) == 0 {
+				return nil, errNoIssueURL
+			}
+
+			var keys []string
+			for _, k := range ip.Keys {
+				keys = append(keys, regexp.QuoteMeta(k))
+			}
+			ip.Pattern = fmt.Sprintf(jiraPattern, strings.Join(keys, "|"))
+		case len(tr) == 0:
+			return nil, errNoIssuePattern
+		default:
+			tp, ok := forgePatterns[tr]
+			if !ok {
+				return nil, errUnknownTracker
+			}
+			ip.Pattern = tp
+			ip.MergeRequest = ip.MergeRequest || tr == MergeRequests
+		}
+
+		rx, err := regexp.Compile(ip.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		ip.rx = rx
+		cps = append(cps, ip)
+	}
+
+	return cps, nil
+}
+
+func mustCompileIssuePatterns(ips []IssuePattern) []IssuePattern {
+	cps, err := compileIssuePatterns(ips)
+	if err != nil {
+		panic(err)
+	}
+	return cps
+}
+
+// findIssues returns the issue references found in the given texts,
+// without duplicates. A text matched by several patterns, ex. "PR #12",
+// is only referenced by the first one.
+func findIssues(txts ...string) []Issue {
+	var iss []Issue
+	seen := make(map[string]bool)
+	taken := make([][][]int, len(txts))
+
+	for _, ip := range issuePatterns {
+		for ti, txt := range txts {
+			for _, loc := range ip.rx.FindAllStringSubmatchIndex(txt, -1) {
+				is, span := ip.newIssue(txt, loc)
+				if seen[is.Ref] || overlaps(taken[ti], span) {
+					continue
+				}
+				seen[is.Ref] = true
+				taken[ti] = append(taken[ti], span)
+
+				if len(ip.URL) != 0 {
+					is.URL = strings.Replace(ip.URL, issueIDPlaceholder, is.ID, -1)
+				}
+				iss = append(iss, is)
+			}
+		}
+	}
+
+	return iss
+}
+
+// newIssue builds the Issue out of the submatch indexes of the pattern,
+// the span of the reference in the text is returned as well.
+func (ip IssuePattern) newIssue(txt string, loc []int) (Issue, []int) {
+	sub := func(idx int) string {
+		if loc[2*idx] == -1 {
+			return ""
+		}
+		return txt[loc[2*idx]:loc[2*idx+1]]
+	}
+
+	is := Issue{Ref: sub(0), ID: sub(0), MergeRequest: ip.MergeRequest}
+	span := []int{loc[0], loc[1]}
+	if len(loc) > 2 {
+		is.ID = sub(1)
+	}
+
+	for idx, name := range ip.rx.SubexpNames() {
+		switch name {
+		case "id":
+			is.ID = sub(idx)
+		case "ref":
+			is.Ref = sub(idx)
+			span = []int{loc[2*idx], loc[2*idx+1]}
+		}
+	}
+
+	return is, span
+}
+
+// overlaps tells whether the span overlaps one of the given ones.
+func overlaps(spans [][]int, span []int) bool {
+	for _, sp := range spans {
+		if span[0] < sp[1] && sp[0] < span[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package message
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxLength is the maximum header length, as per the AngularJS convention.
+const DefaultMaxLength = 100
+
+var (
+	errMissingColon   = errors.New("The type must be followed by a colon")
+	errHeaderTooLong  = errors.New("The subject line is too long")
+	errTrailingPeriod = errors.New("The subject must not end with a period")
+)
+
+// scissorsLine marks the end of the message in verbose commits.
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// exemptPrefixes are generated by git and not meant to follow the convention.
+var exemptPrefixes = []string{"Merge ", "fixup! ", "squash! ", "amend! "}
+
+// Lint checks the given commit message against the current convention,
+// and returns the problems found. A zero max length disables the check.
+func Lint(msg string, maxLength int) []error {
+	var errs []error
+
+	hd := getHeader(msg)
+	if !strings.Contains(hd, ":") {
+		errs = append(errs, errMissingColon)
+	} else if _, err := current.Parse(strings.TrimSpace(msg)); err != nil {
+		errs = append(errs, err)
+	}
+
+	if maxLength > 0 && utf8.RuneCountInString(hd) > maxLength {
+		errs = append(errs, errHeaderTooLong)
+	}
+
+	if strings.HasSuffix(hd, ".") {
+		errs = append(errs, errTrailingPeriod)
+	}
+
+	return errs
+}
+
+// IsExempt tells whether the message has been generated by git,
+// ex. merge commits, fixup! and squash! commits.
+func IsExempt(msg string) bool {
+	hd := getHeader(msg)
+	for _, p := range exemptPrefixes {
+		if strings.HasPrefix(hd, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clean strips the comment lines from a message being committed,
+// as well as the diff following the scissors line of verbose commits.
+func Clean(msg string) string {
+	var ls []string
+	for _, l := range strings.Split(msg, "\n") {
+		if strings.HasPrefix(l, scissorsLine) {
+			break
+		}
+		if strings.HasPrefix(l, "#") {
+			continue
+		}
+		ls = append(ls, strings.TrimRight(l, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(ls, "\n"))
+}
+
+// getHeader returns the first line of the message.
+func getHeader(msg string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(msg), "\n", 2)[0])
+}
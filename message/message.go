@@ -1,14 +1,15 @@
 // Package message wraps the commit message style logic.
 //
-// For now there is only one convention available, the AngularJS one.
-// Read more about it here:
+// Two conventions are available, the AngularJS one (default):
 // https://docs.google.com/document/d/1QrDFcIiPjSLDn3EL15IJygNPiHORgU1_OOAqWjiDU5Y/edit
+// and Conventional Commits 1.0, which accepts any type:
+// https://www.conventionalcommits.org/en/v1.0.0/
 package message
 
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,37 +24,28 @@ var (
 const (
 	simpleFormat   = "%s: %s"
 	extendedFormat = "%s(%s): %s"
-	formatExpr     = `(?si)^([a-z]{3,})(?:\(([\w\d\-_$]+)\))?:([\w\d$@\:\(\)\-\.,'"=&_/\\ ]+)(.+)?`
-
-	// Available Types
-	NA messageType = iota
-	Chore
-	Test
-	Docs
-	Feat
-	Fix
-	Refactor
-	Style
+)
+
+// Well-known types, the Conventional Commits convention accepts any other type.
+const (
+	NA       messageType = ""
+	Chore    messageType = "chore"
+	Test     messageType = "test"
+	Docs     messageType = "docs"
+	Feat     messageType = "feat"
+	Fix      messageType = "fix"
+	Refactor messageType = "refactor"
+	Style    messageType = "style"
 )
 
 type MessageGroup struct {
 	Type, Scope string
 }
 
-type messageType byte
+type messageType string
 
 func (m messageType) String() string {
-	tp, _ := map[messageType]string{
-		Chore:    "chore",
-		Test:     "test",
-		Docs:     "docs",
-		Feat:     "feat",
-		Refactor: "refactor",
-		Style:    "style",
-		Fix:      "fix",
-	}[m]
-
-	return tp
+	return string(m)
 }
 
 type Message struct {
@@ -61,20 +53,26 @@ type Message struct {
 	Scope   string
 	Subject string
 	Body    string
+	Footers []Footer
+
+	// Breaking is set by a "!" before the colon or a BREAKING CHANGE footer
+	Breaking            bool
+	BreakingDescription string
+
+	// Issues are the issue references found in the message, ex. "Closes #12"
+	Issues []Issue
 
 	Date time.Time
 	ID   string
 }
 
 func New(tp interface{}, scope string, subj string) (*Message, error) {
-	var err error
-
 	switch tp.(type) {
 	case string:
-		tp, err = parseMessageType(tp.(string))
-		if err != nil {
-			return nil, err
+		if !current.IsValidType(tp.(string)) {
+			return nil, errInvalidType
 		}
+		tp = messageType(tp.(string))
 	}
 
 	return &Message{
@@ -95,10 +93,52 @@ func (m *Message) String() string {
 	return fmt.Sprintf(extendedFormat, tp, m.Scope, m.Subject)
 }
 
-// GetMessageGroup analyses the given commits, and returns a list of
-// properly deconstructed messages for the current convention.
-func GetMessageGroup(cmts []repository.Commit) (map[string]map[string][]Message, error) {
-	ms := make(map[string]map[string][]Message)
+// Full returns the complete commit message: header, body and footers.
+func (m *Message) Full() string {
+	s := m.String()
+	if len(m.Body) != 0 {
+		s += "\n\n" + m.Body
+	}
+
+	var fs []string
+	for _, f := range m.Footers {
+		fs = append(fs, f.String())
+	}
+	if len(fs) != 0 {
+		s += "\n\n" + strings.Join(fs, "\n")
+	}
+
+	return s
+}
+
+// GetScopes returns the scopes of the given messages,
+// the most used first.
+func GetScopes(ms []Message) []string {
+	cnt := make(map[string]int)
+	var scs []string
+	for _, msg := range ms {
+		if len(msg.Scope) == 0 || msg.Scope == NoScope {
+			continue
+		}
+		if cnt[msg.Scope] == 0 {
+			scs = append(scs, msg.Scope)
+		}
+		cnt[msg.Scope]++
+	}
+
+	sort.SliceStable(scs, func(i, j int) bool {
+		if cnt[scs[i]] != cnt[scs[j]] {
+			return cnt[scs[i]] > cnt[scs[j]]
+		}
+		return scs[i] < scs[j]
+	})
+	return scs
+}
+
+// GetMessages analyses the given commits, and returns the messages
+// following the current convention. The other ones are skipped.
+func GetMessages(cmts []repository.Commit) []Message {
+	var ms []Message
 
 	for _, co := range cmts {
 		msg, err := getMessageFromString(co.Description)
@@ -109,74 +149,41 @@ func GetMessageGroup(cmts []repository.Commit) (map[string]map[string][]Message,
 		msg.ID = co.ID.String()
 		msg.Date = co.Date
 
-		tstr := msg.Type.String()
-
-		if len(msg.Scope) == 0 {
-			msg.Scope = "none"
-		}
-
-		// Init the map
-		_, ok := ms[tstr]
-		if !ok {
-			ms[tstr] = make(map[string][]Message)
-		}
-
-		ms[tstr][msg.Scope] = append(ms[tstr][msg.Scope], *msg)
+		ms = append(ms, *msg)
 	}
 
-	return ms, nil
+	return ms
 }
 
 // getMessageFromString analyses the given commit message,
-// and creates a Message out of it.
+// and creates a Message out of it with the current convention.
 func getMessageFromString(msg string) (*Message, error) {
-	// Initally wanted to use fmt.Sscanf to split the string
-	// but it didn't work as expected: scanning requires items
-	// to be space-separated
-	r, err := regexp.Compile(formatExpr)
-	if err != nil {
-		return nil, err
-	}
+	return current.Parse(msg)
+}
 
-	res := r.FindStringSubmatch(msg)
-	if len(res) != 5 {
-		return nil, errNoMatch
+// setBreakingChange flags the message as breaking if a footer says so,
+// the description falls back on the subject for the "!" notation.
+func (m *Message) setBreakingChange() {
+	for _, f := range m.Footers {
+		if f.IsBreaking() {
+			m.Breaking = true
+			m.BreakingDescription = f.Value
+			return
+		}
 	}
 
-	tp := res[1]
-	scope := res[2]
-	subj := strings.TrimSpace(res[3])
-	body := strings.TrimSpace(res[4])
-
-	stp, err := parseMessageType(tp)
-	if err != nil {
-		return nil, err
+	if m.Breaking {
+		m.BreakingDescription = m.Subject
 	}
-
-	return &Message{
-		Type:    stp,
-		Scope:   scope,
-		Subject: subj,
-		Body:    body,
-	}, nil
 }
 
-// parseMessageType
-func parseMessageType(msg string) (messageType, error) {
-	// Validate the incoming message type
-	tp, ok := map[string]messageType{
-		"chore":    Chore,
-		"test":     Test,
-		"docs":     Docs,
-		"feat":     Feat,
-		"refactor": Refactor,
-		"style":    Style,
-		"fix":      Fix,
-	}[msg]
-
-	if !ok {
-		return NA, errInvalidType
+// FilterBreaking returns the messages introducing a breaking change.
+func FilterBreaking(ms []Message) []Message {
+	var bms []Message
+	for _, msg := range ms {
+		if msg.Breaking {
+			bms = append(bms, msg)
+		}
 	}
-
-	return tp, nil
+	return bms
 }
@@ -1,6 +1,7 @@
 package message
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,6 +57,8 @@ the inject option for the directive controller injection was removed.`
 	assert.NotEmpty(msg.Scope)
 	assert.NotEmpty(msg.Subject)
 	assert.NotEmpty(msg.Body)
+	assert.True(msg.Breaking)
+	assert.NotEmpty(msg.BreakingDescription)
 }
 
 func TestShortMessage(t *testing.T) {
@@ -70,3 +73,269 @@ func TestShortMessage(t *testing.T) {
 	assert.Empty(msg.Scope)
 	assert.Empty(msg.Body)
 }
+
+func TestSubjectCharacters(t *testing.T) {
+	assert := assert.New(t)
+
+	var subjTests = []struct {
+		in, subject string
+	}{
+		{"fix: handle empty messages (#7)", "handle empty messages (#7)"},
+		{"feat: add `foo` flag", "add `foo` flag"},
+		{"feat(api)!: drop v1 <b>", "drop v1 <b>"},
+		{"fix: why not? now!\n\nThe body.", "why not? now!"},
+	}
+
+	for _, tt := range subjTests {
+		msg, err := getMessageFromString(tt.in)
+		assert.Nil(err)
+		assert.Equal(tt.subject, msg.Subject)
+		assert.Empty(Lint(tt.in, DefaultMaxLength))
+	}
+}
+
+func TestBreakingMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	msg, err := getMessageFromString(`feat(api): drop the v1 endpoints
+
+The v2 endpoints are stable.
+
+BREAKING CHANGE: the v1 endpoints are no longer served,
+use the v2 ones instead.
+Closes #12
+Refs: #10, #11`)
+	assert.Nil(err)
+	assert.True(msg.Breaking)
+	assert.Equal("the v1 endpoints are no longer served,\nuse the v2 ones instead.", msg.BreakingDescription)
+	assert.Equal("The v2 endpoints are stable.", msg.Body)
+	assert.Equal([]Footer{
+		{Token: "BREAKING CHANGE", Value: "the v1 endpoints are no longer served,\nuse the v2 ones instead.", Separator: ": "},
+		{Token: "Closes", Value: "#12", Separator: " "},
+		{Token: "Refs", Value: "#10, #11", Separator: ": "},
+	}, msg.Footers)
+	assert.Equal("Refs: #10, #11", msg.Footers[2].String())
+
+	msg, err = getMessageFromString(`refactor(api)!: rename the user resource`)
+	assert.Nil(err)
+	assert.True(msg.Breaking)
+	assert.Equal("api", msg.Scope)
+	assert.Equal("rename the user resource", msg.BreakingDescription)
+
+	msg, err = getMessageFromString(`fix(api): handle empty payloads
+
+Closes #13`)
+	assert.Nil(err)
+	assert.False(msg.Breaking)
+	assert.Empty(msg.Body)
+	assert.Equal([]Footer{{Token: "Closes", Value: "#13", Separator: " "}}, msg.Footers)
+
+	// Only the last paragraph made of footers is the trailer block
+	msg, err = getMessageFromString(`fix(api): handle empty payloads
+
+Note: the payloads used to be required,
+the clients may rely on it.
+
+Warning: retry the requests.
+
+Closes #13`)
+	assert.Nil(err)
+	assert.False(msg.Breaking)
+	assert.Equal("Note: the payloads used to be required,\nthe clients may rely on it.\n\nWarning: retry the requests.", msg.Body)
+	assert.Equal([]Footer{{Token: "Closes", Value: "#13", Separator: " "}}, msg.Footers)
+
+	msg, err = getMessageFromString(`fix(api): handle empty payloads
+
+Note: the payloads used to be required,
+the clients may rely on it.`)
+	assert.Nil(err)
+	assert.Equal("Note: the payloads used to be required,\nthe clients may rely on it.", msg.Body)
+	assert.Empty(msg.Footers)
+}
+
+func TestConventionalMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	cv := &conventional{}
+
+	msg, err := cv.Parse(`perf(parser)!: cache the compiled expressions
+
+Refs: #42`)
+	assert.Nil(err)
+	assert.Equal(messageType("perf"), msg.Type)
+	assert.Equal("parser", msg.Scope)
+	assert.Equal("cache the compiled expressions", msg.Subject)
+	assert.True(msg.Breaking)
+	assert.Equal([]Footer{{Token: "Refs", Value: "#42", Separator: ": "}}, msg.Footers)
+
+	msg, err = cv.Parse(`Revert: let us never again speak of the noodle incident`)
+	assert.Nil(err)
+	assert.Equal(messageType("revert"), msg.Type)
+
+	_, err = cv.Parse(`fixed stuff`)
+	assert.NotNil(err)
+
+	// The space after the colon is mandatory
+	_, err = cv.Parse(`fix:stuff`)
+	assert.NotNil(err)
+
+	// The AngularJS convention drops the unknown types
+	_, err = getMessageFromString(`perf: cache the compiled expressions`)
+	assert.Equal(errInvalidType, err)
+}
+
+func TestSetConvention(t *testing.T) {
+	assert := assert.New(t)
+	defer SetConvention(AngularConvention)
+
+	assert.NotNil(SetConvention("foo"))
+	assert.IsType(&angular{}, GetConvention())
+
+	assert.Nil(SetConvention(ConventionalConvention))
+	assert.IsType(&conventional{}, GetConvention())
+
+	msg, err := New("ci", "", "run the tests on every push")
+	assert.Nil(err)
+	assert.Equal("ci: run the tests on every push", msg.String())
+}
+
+func TestSections(t *testing.T) {
+	assert := assert.New(t)
+	defer SetTypes(nil)
+
+	ms := []Message{
+		{Type: Chore, Subject: "bump the dependencies"},
+		{Type: Fix, Scope: "parser", Subject: "handle empty messages"},
+		{Type: "perf", Subject: "cache the expressions"},
+		{Type: Feat, Scope: "cli", Subject: "add the lint command"},
+		{Type: Feat, Subject: "add the hook command"},
+		{Type: "wip", Subject: "work in progress"},
+	}
+
+	// Default order, undeclared types are appended
+	ss := GetSections(ms)
+	assert.Len(ss, 5)
+	assert.Equal("Features", ss[0].Title)
+	assert.Equal([]string{"none", "cli"}, []string{ss[0].Scopes[0].Scope, ss[0].Scopes[1].Scope})
+	assert.Equal("Bug Fixes", ss[1].Title)
+	assert.Equal("Performance Improvements", ss[2].Title)
+	assert.Equal("Chores", ss[3].Title)
+	assert.Equal("Wip", ss[4].Title)
+
+	SetTypes([]TypeConfig{
+		{Type: "perf", Section: "Performance"},
+		{Type: "feat", Section: "New Features"},
+		{Type: "fix"},
+		{Type: "chore", Hidden: true},
+	})
+
+	ss = GetSections(ms)
+	assert.Len(ss, 3)
+	assert.Equal("Performance", ss[0].Title)
+	assert.Equal("New Features", ss[1].Title)
+	assert.Equal("Fix", ss[2].Title)
+
+	// The declared types are the accepted ones
+	_, err := getMessageFromString(`chore: bump the dependencies`)
+	assert.Nil(err)
+	_, err = getMessageFromString(`perf: cache the expressions`)
+	assert.Nil(err)
+	_, err = getMessageFromString(`docs: document the hook command`)
+	assert.Equal(errInvalidType, err)
+}
+
+func TestLint(t *testing.T) {
+	assert := assert.New(t)
+
+	var lintTests = []struct {
+		in       string
+		expected []error
+	}{
+		{"feat(lint): add the lint command", nil},
+		{"fixed stuff", []error{errMissingColon}},
+		{"fixes: the parser", []error{errInvalidType}},
+		{"fix(parser): handle empty messages.", []error{errTrailingPeriod}},
+		{"fix: " + strings.Repeat("a", DefaultMaxLength), []error{errHeaderTooLong}},
+		{"wip.", []error{errMissingColon, errTrailingPeriod}},
+	}
+
+	for _, tt := range lintTests {
+		assert.Equal(tt.expected, Lint(tt.in, DefaultMaxLength), tt.in)
+	}
+
+	assert.Empty(Lint("fix: "+strings.Repeat("a", DefaultMaxLength), 0))
+
+	assert.True(IsExempt("Merge branch 'master' into feature"))
+	assert.True(IsExempt("fixup! feat(lint): add the lint command"))
+	assert.False(IsExempt("feat(lint): add the lint command"))
+}
+
+func TestClean(t *testing.T) {
+	assert := assert.New(t)
+
+	msg := Clean(`feat(hook): add the commit-msg hook
+
+Checks the message before committing.
+# Please enter the commit message for your changes. Lines starting
+# with '#' will be ignored, and an empty message aborts the commit.
+# ------------------------ >8 ------------------------
+diff --git a/hook/hook.go b/hook/hook.go
+`)
+	assert.Equal("feat(hook): add the commit-msg hook\n\nChecks the message before committing.", msg)
+	assert.Empty(Lint(msg, DefaultMaxLength))
+}
+
+func TestIssues(t *testing.T) {
+	assert := assert.New(t)
+
+	desc := `fix(parser): handle empty messages (#7)
+
+The parser no longer panics, see https://example.com/page#12 and PR&#34;.
+
+Fixes JIRA-456
+Closes #12, #7`
+
+	// GitHub references only by default
+	m, err := getMessageFromString(desc)
+	assert.Nil(err)
+	assert.Equal("handle empty messages (#7)", m.Subject)
+	assert.Equal([]Issue{{Ref: "#7", ID: "7"}, {Ref: "#12", ID: "12"}}, m.Issues)
+
+	// Merge requests are told apart from the issues
+	m, err = getMessageFromString("fix: handle empty messages\n\nFollows !34 and PR #56, see #56 and hello!78")
+	assert.Nil(err)
+	assert.Equal([]Issue{
+		{Ref: "!34", ID: "34", MergeRequest: true},
+		{Ref: "PR #56", ID: "56", MergeRequest: true},
+		{Ref: "#56", ID: "56"},
+	}, m.Issues)
+
+	err = SetIssuePatterns([]IssuePattern{
+		{Tracker: GitHubIssues},
+		{Tracker: JiraIssues, Keys: []string{"JIRA", "OPS"}, URL: "https://jira.example.com/browse/{id}"},
+		{Pattern: `gh-(\d+)`, URL: "https://tracker.example.com/{id}"},
+	})
+	assert.Nil(err)
+	defer SetIssuePatterns(nil)
+
+	m, err = getMessageFromString(desc + "\nRefs: gh-3")
+	assert.Nil(err)
+	assert.Equal([]Issue{
+		{Ref: "#7", ID: "7"},
+		{Ref: "#12", ID: "12"},
+		{Ref: "JIRA-456", ID: "JIRA-456", URL: "https://jira.example.com/browse/JIRA-456"},
+		{Ref: "gh-3", ID: "3", URL: "https://tracker.example.com/3"},
+	}, m.Issues)
+
+	// Only the configured Jira keys are references
+	m, err = getMessageFromString("fix: encode the names in UTF-8\n\nSHA-256, ISO-8601 and RFC-2119 are kept, OPSX-1 and OPS-2 are fixed")
+	assert.Nil(err)
+	assert.Equal([]Issue{{Ref: "OPS-2", ID: "OPS-2", URL: "https://jira.example.com/browse/OPS-2"}}, m.Issues)
+
+	assert.Equal(errUnknownTracker, SetIssuePatterns([]IssuePattern{{Tracker: "redmine"}}))
+	assert.Equal(errNoIssuePattern, SetIssuePatterns([]IssuePattern{{URL: "https://example.com/{id}"}}))
+	assert.Equal(errNoJiraKeys, SetIssuePatterns([]IssuePattern{{Tracker: JiraIssues, URL: "https://example.com/{id}"}}))
+	assert.Equal(errNoIssueURL, SetIssuePatterns([]IssuePattern{{Tracker: JiraIssues, Keys: []string{"JIRA"}}}))
+	assert.Equal(errNoIssueURL, SetIssuePatterns([]IssuePattern{{Pattern: `gh-(\d+)`}}))
+	assert.NotNil(SetIssuePatterns([]IssuePattern{{Pattern: `(`, URL: "https://example.com/{id}"}}))
+}
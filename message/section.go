@@ -0,0 +1,156 @@
+package message
+
+import "sort"
+
+// NoScope groups the messages without scope, ex. in the templates.
+const NoScope = "none"
+
+// TypeConfig describes how a commit type is handled in the changelog.
+type TypeConfig struct {
+	Type string
+
+	// Section is the changelog header, ex. "Bug Fixes"
+	Section string
+
+	// Hidden types are accepted but left out of the changelog
+	Hidden bool
+}
+
+// Section gathers the messages of a given type, by scope.
+type Section struct {
+	Type   string
+	Title  string
+	Scopes []ScopeGroup
+}
+
+// ScopeGroup gathers the messages of a given scope.
+type ScopeGroup struct {
+	Scope    string
+	Messages []Message
+}
+
+// defaultTypes is used when no type has been declared,
+// the types missing from the list are appended alphabetically.
+var defaultTypes = []TypeConfig{
+	{Type: "feat", Section: "Features"},
+	{Type: "fix", Section: "Bug Fixes"},
+	{Type: "perf", Section: "Performance Improvements"},
+	{Type: "revert", Section: "Reverts"},
+	{Type: "refactor", Section: "Code Refactoring"},
+	{Type: "docs", Section: "Documentation"},
+	{Type: "style", Section: "Styles"},
+	{Type: "test", Section: "Tests"},
+	{Type: "build", Section: "Build System"},
+	{Type: "ci", Section: "Continuous Integration"},
+	{Type: "chore", Section: "Chores"},
+}
+
+// declaredTypes are the types accepted by the conventions, in display order.
+var declaredTypes []TypeConfig
+
+// SetTypes declares the accepted types, their section titles and
+// their display order. An empty list restores the defaults.
+func SetTypes(tcs []TypeConfig) {
+	declaredTypes = tcs
+}
+
+// isDeclaredType tells whether the given type has been declared,
+// ok is false when no type has been declared at all.
+func isDeclaredType(tp string) (declared bool, ok bool) {
+	if len(declaredTypes) == 0 {
+		return false, false
+	}
+
+	for _, tc := range declaredTypes {
+		if tc.Type == tp {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// getTypeConfigs returns the declared types, or the defaults.
+func getTypeConfigs() []TypeConfig {
+	if len(declaredTypes) != 0 {
+		return declaredTypes
+	}
+	return defaultTypes
+}
+
+// GetSections groups the given messages by type then scope,
+// following the declared order. Hidden types are skipped.
+func GetSections(ms []Message) []Section {
+	bt := make(map[string][]Message)
+	for _, msg := range ms {
+		bt[msg.Type.String()] = append(bt[msg.Type.String()], msg)
+	}
+
+	var ss []Section
+
+	tcs := getTypeConfigs()
+	for _, tc := range tcs {
+		tms, ok := bt[tc.Type]
+		delete(bt, tc.Type)
+		if !ok || tc.Hidden {
+			continue
+		}
+
+		title := tc.Section
+		if len(title) == 0 {
+			title = capitalize(tc.Type)
+		}
+		ss = append(ss, NewSection(tc.Type, title, tms))
+	}
+
+	// The undeclared types are appended to the default list
+	var rest []string
+	for tp := range bt {
+		if len(declaredTypes) == 0 {
+			rest = append(rest, tp)
+		}
+	}
+	sort.Strings(rest)
+	for _, tp := range rest {
+		ss = append(ss, NewSection(tp, capitalize(tp), bt[tp]))
+	}
+
+	return ss
+}
+
+// NewSection groups the given messages by scope,
+// the messages without scope come first.
+func NewSection(tp, title string, ms []Message) Section {
+	bs := make(map[string][]Message)
+	for _, msg := range ms {
+		sc := msg.Scope
+		if len(sc) == 0 {
+			sc = NoScope
+		}
+		bs[sc] = append(bs[sc], msg)
+	}
+
+	var scs []string
+	for sc := range bs {
+		if sc != NoScope {
+			scs = append(scs, sc)
+		}
+	}
+	sort.Strings(scs)
+	if _, ok := bs[NoScope]; ok {
+		scs = append([]string{NoScope}, scs...)
+	}
+
+	s := Section{Type: tp, Title: title}
+	for _, sc := range scs {
+		s.Scopes = append(s.Scopes, ScopeGroup{Scope: sc, Messages: bs[sc]})
+	}
+	return s
+}
+
+// capitalize upper-cases the first letter of the given ASCII type, ex. "docs" => "Docs".
+func capitalize(s string) string {
+	if len(s) == 0 || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
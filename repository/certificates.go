@@ -0,0 +1,257 @@
+// Package repository wraps and simplifies the libgit2 bindings
+// exposed in the git2go library.
+// This specific file contains all the code related to certificate checks.
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/libgit2/git2go"
+)
+
+const (
+	knownHostsFile  = "~/.ssh/known_hosts"
+	hashedHostMagic = "|1|"
+
+	markerRevoked       = "@revoked"
+	markerCertAuthority = "@cert-authority"
+)
+
+var (
+	errUnknownCertificate = errors.New("The certificate type is not supported")
+	errNoX509             = errors.New("No X509 certificate has been provided")
+	errNoHostkeyHash      = errors.New("No host key hash has been provided")
+	errUnknownHost        = errors.New("The host is not listed in the known hosts file")
+	errHostkeyMismatch    = errors.New("The host key does not match the known hosts file, possible man-in-the-middle attack")
+	errHostkeyRevoked     = errors.New("The host key has been revoked")
+)
+
+// knownHost is an entry of the known_hosts file.
+type knownHost struct {
+	marker string
+	hosts  []string
+	key    []byte
+}
+
+// certificateCheckCallback is linked to the git.RemoteCallbacks
+func (r *Repository) certificateCheckCallback(cert *git.Certificate, valid bool, hostname string) git.ErrorCode {
+	if r.credentials.Push.InsecureSkipVerify {
+		log.Warnf("The certificate verification is skipped for %s", hostname)
+		return git.ErrOk
+	}
+
+	var err error
+	switch cert.Kind {
+	case git.CertificateX509:
+		err = verifyX509(cert.X509, valid, hostname)
+	case git.CertificateHostkey:
+		err = r.verifyHostkey(cert.Hostkey, hostname)
+	default:
+		err = errUnknownCertificate
+	}
+
+	if err != nil {
+		log.Errorf("Certificate check failed for %s: %s", hostname, err)
+		return git.ErrCertificate
+	}
+
+	return git.ErrOk
+}
+
+// verifyX509 checks the HTTPS certificate against the system pool,
+// unless libgit2 already validated it.
+func verifyX509(cert *x509.Certificate, valid bool, hostname string) error {
+	if valid {
+		return nil
+	}
+
+	if cert == nil {
+		return errNoX509
+	}
+
+	// Leaving the roots empty uses the system pool
+	_, err := cert.Verify(x509.VerifyOptions{DNSName: hostname})
+	return err
+}
+
+// verifyHostkey checks the SSH host key against the known hosts file.
+func (r *Repository) verifyHostkey(hk git.HostkeyCertificate, hostname string) error {
+	path := r.credentials.Push.KnownHosts
+	if len(path) == 0 {
+		path = knownHostsFile
+	}
+
+	path, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return checkKnownHosts(parseKnownHosts(dat), hk, hostname)
+}
+
+// checkKnownHosts looks for the given host key in the known hosts entries.
+func checkKnownHosts(khs []knownHost, hk git.HostkeyCertificate, hostname string) error {
+	if hk.Kind&(git.HostkeySHA1|git.HostkeyMD5) == 0 {
+		return errNoHostkeyHash
+	}
+
+	var found bool
+	for _, kh := range khs {
+		if !kh.matchHost(hostname) {
+			continue
+		}
+
+		switch kh.marker {
+		case markerRevoked:
+			if kh.matchKey(hk) {
+				return errHostkeyRevoked
+			}
+		case "":
+			if kh.matchKey(hk) {
+				return nil
+			}
+			found = true
+		}
+	}
+
+	if found {
+		return errHostkeyMismatch
+	}
+
+	return errUnknownHost
+}
+
+// parseKnownHosts extracts the entries of a known_hosts file,
+// the invalid lines are skipped.
+func parseKnownHosts(dat []byte) []knownHost {
+	var khs []knownHost
+
+	sc := bufio.NewScanner(bytes.NewReader(dat))
+	for sc.Scan() {
+		fs := strings.Fields(sc.Text())
+		if len(fs) == 0 || strings.HasPrefix(fs[0], "#") {
+			continue
+		}
+
+		var kh knownHost
+		if strings.HasPrefix(fs[0], "@") {
+			kh.marker = fs[0]
+			fs = fs[1:]
+		}
+
+		// CA keys sign host certificates, which libgit2 does not expose
+		if len(fs) < 3 || kh.marker == markerCertAuthority {
+			continue
+		}
+
+		key, err := base64.StdEncoding.DecodeString(fs[2])
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+
+		kh.hosts = strings.Split(fs[0], ",")
+		kh.key = key
+		khs = append(khs, kh)
+	}
+
+	return khs
+}
+
+// matchHost tells whether the entry applies to the given host name,
+// hashed entries and wildcards are supported.
+func (kh knownHost) matchHost(hostname string) bool {
+	var match bool
+
+	for _, h := range kh.hosts {
+		negated := strings.HasPrefix(h, "!")
+		h = strings.TrimPrefix(h, "!")
+
+		if matchHostPattern(h, hostname) {
+			// A negated pattern excludes the host from the whole entry
+			if negated {
+				return false
+			}
+			match = true
+		}
+	}
+
+	return match
+}
+
+// matchKey compares the host key hashes with the entry's key.
+func (kh knownHost) matchKey(hk git.HostkeyCertificate) bool {
+	if hk.Kind&git.HostkeySHA1 != 0 {
+		return sha1.Sum(kh.key) == hk.HashSHA1
+	}
+	return md5.Sum(kh.key) == hk.HashMD5
+}
+
+// matchHostPattern matches a single host pattern, the port of
+// "[host]:port" patterns is ignored since libgit2 only gives the host.
+func matchHostPattern(pattern, hostname string) bool {
+	if strings.HasPrefix(pattern, hashedHostMagic) {
+		return matchHashedHost(pattern, hostname)
+	}
+
+	if strings.HasPrefix(pattern, "[") {
+		if idx := strings.Index(pattern, "]"); idx != -1 {
+			pattern = pattern[1:idx]
+		}
+	}
+
+	ok, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(hostname))
+	return err == nil && ok
+}
+
+// matchHashedHost matches the hashed form |1|salt|hash of a host.
+func matchHashedHost(pattern, hostname string) bool {
+	ps := strings.Split(strings.TrimPrefix(pattern, hashedHostMagic), "|")
+	if len(ps) != 2 {
+		return false
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(ps[0])
+	if err != nil {
+		return false
+	}
+	hash, err := base64.StdEncoding.DecodeString(ps[1])
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(hostname))
+	return hmac.Equal(mac.Sum(nil), hash)
+}
+
+// expandHome replaces the leading ~ with the home folder.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(usr.HomeDir, path[2:]), nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/libgit2/git2go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKnownHosts(t *testing.T) {
+	assert := assert.New(t)
+
+	key := []byte("ssh-rsa host key")
+	other := []byte("ssh-rsa other key")
+
+	// Hashed entry for hashed.example.com
+	salt := []byte("0123456789abcdefghij")
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte("hashed.example.com"))
+	hashed := fmt.Sprintf("|1|%s|%s", base64.StdEncoding.EncodeToString(salt), base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	khs := parseKnownHosts([]byte(fmt.Sprintf(`# comment
+github.com,192.30.252.1 ssh-rsa %[1]s
+[gitlab.example.com]:2222 ssh-rsa %[1]s
+*.internal,!evil.internal ssh-rsa %[1]s
+%[3]s ssh-rsa %[1]s
+rotated.example.com ssh-rsa %[2]s
+@revoked revoked.example.com ssh-rsa %[1]s
+@cert-authority *.example.com ssh-rsa %[1]s
+broken line
+`, base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(other), hashed)))
+	assert.Len(khs, 6)
+
+	hk := git.HostkeyCertificate{Kind: git.HostkeySHA1, HashSHA1: sha1.Sum(key)}
+	bad := git.HostkeyCertificate{Kind: git.HostkeySHA1, HashSHA1: sha1.Sum(other)}
+
+	assert.Nil(checkKnownHosts(khs, hk, "github.com"))
+	assert.Nil(checkKnownHosts(khs, hk, "192.30.252.1"))
+	assert.Nil(checkKnownHosts(khs, hk, "gitlab.example.com"))
+	assert.Nil(checkKnownHosts(khs, hk, "git.internal"))
+	assert.Nil(checkKnownHosts(khs, hk, "hashed.example.com"))
+
+	assert.Nil(checkKnownHosts(khs, bad, "rotated.example.com"))
+	assert.Equal(errHostkeyMismatch, checkKnownHosts(khs, bad, "github.com"))
+	assert.Equal(errHostkeyMismatch, checkKnownHosts(khs, hk, "rotated.example.com"))
+	assert.Equal(errUnknownHost, checkKnownHosts(khs, hk, "evil.internal"))
+	assert.Equal(errUnknownHost, checkKnownHosts(khs, hk, "bitbucket.org"))
+	assert.Equal(errHostkeyRevoked, checkKnownHosts(khs, hk, "revoked.example.com"))
+	assert.Equal(errNoHostkeyHash, checkKnownHosts(khs, git.HostkeyCertificate{}, "github.com"))
+}
@@ -18,8 +18,11 @@ const (
 	gitConfigFilename = ".gitconfig"
 	gitConfigExpr     = `(?si)\[user\].+name\s=\s([\w\d ]+).+email\s=\s([\w\d@\.]+)`
 
-	strategySSHAgent = "ssh-agent"
-	strategySSHKey   = "ssh-key"
+	strategySSHAgent   = "ssh-agent"
+	strategySSHKey     = "ssh-key"
+	strategyHTTPSToken = "https-token"
+	strategyUserPass   = "userpass"
+	strategyNone       = "none"
 )
 
 // Credentials contains the details of the user who's doing the push
@@ -36,15 +39,34 @@ type User struct {
 }
 
 // Push holds the configuration about the git push strategy.
+// The token is used by https-token, the password by userpass.
+// The known hosts file defaults to ~/.ssh/known_hosts.
 type Push struct {
 	Strategy, Username                string
 	PublicKey, PrivateKey, Passphrase string
+	Token, Password                   string
+	KnownHosts                        string
+	InsecureSkipVerify                bool
+}
+
+// isSSH tells whether the push strategy relies on SSH.
+func (p *Push) isSSH() bool {
+	return p.Strategy == strategySSHKey || p.Strategy == strategySSHAgent
 }
 
 // SetCredentials sets the informations required for signing
-// and pushing Git changes.
-func (r *Repository) SetCredentials(creds *Credentials) {
+// and pushing Git changes. The push strategy may be left unset.
+func (r *Repository) SetCredentials(creds *Credentials) error {
+	if creds.Push != nil {
+		switch creds.Push.Strategy {
+		case "", strategySSHKey, strategySSHAgent, strategyHTTPSToken, strategyUserPass, strategyNone:
+		default:
+			return errUnknownStrategy
+		}
+	}
+
 	r.credentials = creds
+	return nil
 }
 
 // GetSignature returns the user infos required for
@@ -57,7 +79,8 @@ func (r *Repository) GetSignature() *git.Signature {
 	}
 }
 
-// credentialsCallback is linked to the git.RemoteCallbacks
+// credentialsCallback is linked to the git.RemoteCallbacks.
+// Without strategy, libgit2 falls back to its own credentials lookup.
 func (r *Repository) credentialsCallback(url string, username string, allowedTypes git.CredType) (git.ErrorCode, *git.Cred) {
 	var ret int
 	var cred git.Cred
@@ -69,16 +92,21 @@ func (r *Repository) credentialsCallback(url string, username string, allowedTyp
 	case strategySSHAgent:
 		ret, cred = git.NewCredSshKeyFromAgent(r.credentials.Push.Username)
 		break
+	case strategyHTTPSToken:
+		ret, cred = git.NewCredUserpassPlaintext(r.credentials.Push.Username, r.credentials.Push.Token)
+		break
+	case strategyUserPass:
+		ret, cred = git.NewCredUserpassPlaintext(r.credentials.Push.Username, r.credentials.Push.Password)
+		break
+	case "", strategyNone:
+		return git.ErrPassthrough, nil
+	default:
+		return git.ErrAuth, nil
 	}
 
 	return git.ErrorCode(ret), &cred
 }
 
-// certificateCheckCallback is linked to the git.RemoteCallbacks
-func (r *Repository) certificateCheckCallback(cert *git.Certificate, valid bool, hostname string) git.ErrorCode {
-	return 0
-}
-
 // GetUserFromGitConfig extracts from the local git config the
 // username and email address of the git user if already configured.
 func GetUserFromGitConfig() *User {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -15,24 +16,34 @@ import (
 	"github.com/libgit2/git2go"
 )
 
+// DefaultRemote is the remote used when none is configured.
+const DefaultRemote = "origin"
+
 var (
-	errNoTagFound = errors.New("No semver tag has been found")
-	errNoURLMatch = errors.New("No URL could be matched")
+	errNoTagFound      = errors.New("No semver tag has been found")
+	errNoURLMatch      = errors.New("No URL could be matched")
+	errNoToken         = errors.New("The https-token push strategy requires a token")
+	errNothingToCommit = errors.New("Nothing to commit, stage the changes first")
+	errUnknownStrategy = errors.New("The given push strategy is not supported")
 )
 
 // Repository contains the original git.Repository object plus a few more
 // useful things, such as the repository path on the FS, the credentials...
 type Repository struct {
-	path        string
-	repository  *git.Repository
-	credentials *Credentials
+	path          string
+	repository    *git.Repository
+	credentials   *Credentials
+	remotes       []string
+	reachableOnly bool
 }
 
 // Tag holds the information about a given tag.
 type Tag struct {
-	Name   string
-	Date   time.Time
-	Target *git.Oid
+	Name    string
+	Version semver.Version
+	Date    time.Time
+	Message string
+	Target  *git.Oid
 }
 
 // Commit holds the information about a given commit.
@@ -42,31 +53,29 @@ type Commit struct {
 	ID          *git.Oid
 }
 
-type timeSlice []Tag
+type versionSlice []Tag
+
+// IsNoTagFound tells whether the given error reports that
+// the repository has no matching semver tag.
+func IsNoTagFound(err error) bool {
+	return err == errNoTagFound
+}
 
 // Forward request for length
-func (p timeSlice) Len() int {
+func (p versionSlice) Len() int {
 	return len(p)
 }
 
-// Define compare
-func (p timeSlice) Less(i, j int) bool {
-	return p[i].Date.Before(p[j].Date)
+// Define compare, following the semver precedence
+func (p versionSlice) Less(i, j int) bool {
+	return p[i].Version.LessThan(p[j].Version)
 }
 
 // Define swap over an array
-func (p timeSlice) Swap(i, j int) {
+func (p versionSlice) Swap(i, j int) {
 	p[i], p[j] = p[j], p[i]
 }
 
-func (p timeSlice) GetSlice() []Tag {
-	var ts []Tag
-	for _, t := range p {
-		ts = append(ts, t)
-	}
-	return ts
-}
-
 // New returns a new instance of Repository
 func New(path string) (*Repository, error) {
 	var err error
@@ -96,21 +105,62 @@ func (r *Repository) GetRepository() *git.Repository {
 	return r.repository
 }
 
-// GetTags returns the tags list for the current repository.
+// SetRemotes sets the remotes the tags are pushed to,
+// the first one is used for the URL lookups.
+func (r *Repository) SetRemotes(rms ...string) {
+	r.remotes = rms
+}
+
+// GetRemotes returns the configured remotes, defaults to origin.
+func (r *Repository) GetRemotes() []string {
+	if len(r.remotes) == 0 {
+		return []string{DefaultRemote}
+	}
+	return r.remotes
+}
+
+// SetReachableTagsOnly restricts the tags list to the ones
+// reachable from HEAD, useful with maintenance branches.
+func (r *Repository) SetReachableTagsOnly(ro bool) {
+	r.reachableOnly = ro
+}
+
+// GetHooksPath returns the folder of the git hooks,
+// core.hooksPath if configured or .git/hooks.
+func (r *Repository) GetHooksPath() (string, error) {
+	cfg, err := r.repository.Config()
+	if err != nil {
+		return "", err
+	}
+
+	hp, err := cfg.LookupString("core.hooksPath")
+	if err == nil && len(hp) != 0 {
+		if !filepath.IsAbs(hp) {
+			hp = filepath.Join(r.repository.Workdir(), hp)
+		}
+		return hp, nil
+	}
+
+	return filepath.Join(r.repository.Path(), "hooks"), nil
+}
+
+// GetTags returns the semver tags list for the current repository,
+// sorted by version precedence.
 func (r *Repository) GetTags() ([]Tag, error) {
 	var ts []Tag
 
 	// Retrieve the tags
 	err := r.repository.Tags.Foreach(func(name string, id *git.Oid) error {
 		tn := strings.Replace(name, "refs/tags/", "", -1)
+		if !semver.IsValid(tn) {
+			return nil
+		}
+
 		tg, err := r.buildTag(tn, id)
 		if err != nil {
 			return err
 		}
-
-		if semver.IsValid(tn) {
-			ts = append(ts, tg)
-		}
+		ts = append(ts, tg)
 
 		return nil
 	})
@@ -119,20 +169,23 @@ func (r *Repository) GetTags() ([]Tag, error) {
 		return nil, err
 	}
 
-	st := make(timeSlice, 0, len(ts))
-	for _, d := range ts {
-		st = append(st, d)
+	if r.reachableOnly {
+		ts, err = r.filterReachableTags(ts)
+		if err != nil {
+			return nil, err
+		}
 	}
-	sort.Sort(st)
 
-	if len(st) == 0 {
+	sort.Stable(versionSlice(ts))
+
+	if len(ts) == 0 {
 		return nil, errNoTagFound
 	}
 
-	return st.GetSlice(), nil
+	return ts, nil
 }
 
-// GetLastTag returns the latest tag pushed.
+// GetLastTag returns the tag with the highest version.
 func (r *Repository) GetLastTag() (Tag, error) {
 	tags, err := r.GetTags()
 	if err != nil {
@@ -142,10 +195,8 @@ func (r *Repository) GetLastTag() (Tag, error) {
 	return tags[len(tags)-1], nil
 }
 
-// CreateAndPushTag tags a repository and then pushes it automatically.
-func (r *Repository) CreateAndPushTag(t string, msg string) error {
-	var err error
-
+// CreateTag creates an annotated tag on HEAD.
+func (r *Repository) CreateTag(t string, msg string) error {
 	head, err := r.repository.Head()
 	if err != nil {
 		return err
@@ -159,42 +210,102 @@ func (r *Repository) CreateAndPushTag(t string, msg string) error {
 	}
 	defer commit.Free()
 
-	if _, err = r.repository.Tags.Create(t, commit, r.GetSignature(), msg); err != nil {
-		return err
+	_, err = r.repository.Tags.Create(t, commit, r.GetSignature(), msg)
+	return err
+}
+
+// PushTag pushes the given tags to the given remote.
+func (r *Repository) PushTag(remote string, tags ...string) error {
+	if r.credentials.Push.Strategy == strategyHTTPSToken && len(r.credentials.Push.Token) == 0 {
+		return errNoToken
 	}
 
-	// Retrieve the *Remote
-	rm, err := r.repository.Remotes.Lookup("origin")
+	rm, err := r.repository.Remotes.Lookup(remote)
 	if err != nil {
 		return err
 	}
-	defer rm.Free()
 
 	// Set the proper push URL
 	// When using the credentials git.NewCredSshKey*, the URL must SSH typed:
 	// user@repo.com/my/repo
-	url, err := r.getSSHPushURL(rm.Url())
+	url, err := r.getPushURL(rm.Url())
+	rm.Free()
+	if err != nil {
+		return err
+	}
+	err = r.repository.Remotes.SetPushUrl(remote, url)
 	if err != nil {
 		return err
 	}
-	err = r.repository.Remotes.SetPushUrl("origin", url)
+
+	// Retrieve the *Remote again, now aware of the push URL
+	rm, err = r.repository.Remotes.Lookup(remote)
 	if err != nil {
 		return err
 	}
+	defer rm.Free()
 
 	co := &git.PushOptions{
 		RemoteCallbacks: git.RemoteCallbacks{
-			CredentialsCallback:      r.credentialsCallback,
 			CertificateCheckCallback: r.certificateCheckCallback,
 		},
 	}
 
-	// Push the tag
-	if err = rm.Push([]string{fmt.Sprintf("refs/tags/%s", t)}, co); err != nil {
-		return err
+	// Local remotes do not require any authentication
+	if r.credentials.Push.Strategy != strategyNone {
+		co.RemoteCallbacks.CredentialsCallback = r.credentialsCallback
+	}
+
+	refs := make([]string, 0, len(tags))
+	for _, t := range tags {
+		refs = append(refs, fmt.Sprintf("refs/tags/%s", t))
+	}
+
+	// Push the tags
+	return rm.Push(refs, co)
+}
+
+// CreateCommit commits the staged changes on HEAD with the given message,
+// signed with the user credentials.
+func (r *Repository) CreateCommit(msg string) (*git.Oid, error) {
+	idx, err := r.repository.Index()
+	if err != nil {
+		return nil, err
+	}
+	defer idx.Free()
+
+	tid, err := idx.WriteTree()
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := r.repository.LookupTree(tid)
+	if err != nil {
+		return nil, err
 	}
+	defer tree.Free()
 
-	return nil
+	var parents []*git.Commit
+
+	// HEAD is unborn on the first commit
+	head, err := r.repository.Head()
+	if err == nil {
+		defer head.Free()
+
+		parent, err := r.repository.LookupCommit(head.Target())
+		if err != nil {
+			return nil, err
+		}
+		defer parent.Free()
+
+		if parent.TreeId().Equal(tid) {
+			return nil, errNothingToCommit
+		}
+		parents = append(parents, parent)
+	}
+
+	sig := r.GetSignature()
+	return r.repository.CreateCommit("HEAD", sig, sig, msg, tree, parents...)
 }
 
 // GetTag inspects the tag list and tries to match the given tag
@@ -203,7 +314,6 @@ func (r *Repository) GetTag(tag string) (Tag, error) {
 	var err error
 	var it *git.ReferenceIterator
 	var tg Tag
-	var emptyTag Tag
 
 	if it, err = r.repository.NewReferenceIteratorGlob("refs/tags/*"); err != nil {
 		return tg, err
@@ -225,107 +335,124 @@ func (r *Repository) GetTag(tag string) (Tag, error) {
 
 		tg, err = r.buildTag(tn, tr.Target())
 		if err != nil {
-			return emptyTag, err
+			return Tag{}, err
 		}
 
 		break
 	}
 
-	if tg == emptyTag {
-		return emptyTag, errNoTagFound
+	if tg.Target == nil {
+		return Tag{}, errNoTagFound
 	}
 
 	return tg, nil
 }
 
-// GetPreviousTagFor retrieves the previous Tag for the given Tag.
+// GetPreviousTagFor retrieves the tag preceding the given Tag
+// in the semver precedence.
 func (r *Repository) GetPreviousTagFor(tag Tag) (Tag, error) {
-	var tg Tag
-
 	tgs, err := r.GetTags()
 	if err != nil {
-		return tg, err
+		return Tag{}, err
 	}
 
-	for idx, tg := range tgs {
-		if tg.Name == tag.Name {
-			// Cannot retrieve the previous tag if there is no previous tag
-			if idx != 0 {
-				return tgs[idx-1], nil
-			}
+	// The tags are sorted, the last lower one is the previous tag
+	for idx := len(tgs) - 1; idx >= 0; idx-- {
+		if tgs[idx].Version.LessThan(tag.Version) {
+			return tgs[idx], nil
 		}
 	}
 
-	return tg, errNoTagFound
+	return Tag{}, errNoTagFound
 }
 
 // GetCommitListForTag returns the list of commits associated
-// with the given Tag.
+// with the given Tag. The first tag holds the whole history up to it.
 func (r *Repository) GetCommitListForTag(tag Tag) ([]Commit, error) {
 	var err error
 
 	ptag, err := r.GetPreviousTagFor(tag)
+	if err == errNoTagFound {
+		return r.GetCommitListUntilTag(tag)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Initialize and configure the rev walk
-	rv, _ := r.repository.Walk()
-	defer rv.Free()
-	rv.Sorting(git.SortTime)
+	return r.getCommitList(tag.Target, ptag.Target)
+}
+
+// GetCommitListUntilTag returns the list of commits from the root
+// of the history up to the given Tag.
+func (r *Repository) GetCommitListUntilTag(tag Tag) ([]Commit, error) {
+	return r.getCommitList(tag.Target, nil)
+}
 
-	// Start iterating from the tag's reference
-	err = rv.Push(tag.Target)
+// GetCommitListSinceTag returns the list of commits made
+// on HEAD after the given Tag.
+func (r *Repository) GetCommitListSinceTag(tag Tag) ([]Commit, error) {
+	head, err := r.repository.Head()
 	if err != nil {
 		return nil, err
 	}
+	defer head.Free()
+
+	return r.getCommitList(head.Target(), tag.Target)
+}
+
+// GetCommitsBetween returns the list of commits reachable from the "to"
+// revision but not from the "from" one, both can be a tag, a branch or a SHA.
+// An empty "from" starts from the root of the history, an empty "to" defaults to HEAD.
+func (r *Repository) GetCommitsBetween(from, to string) ([]Commit, error) {
+	if len(to) == 0 {
+		to = "HEAD"
+	}
 
-	// Iterate until the previous tag
-	err = rv.Hide(ptag.Target)
+	tid, err := r.resolveCommit(to)
 	if err != nil {
 		return nil, err
 	}
 
-	var cmts []Commit
-
-	var gi git.Oid
-	for {
-		err = rv.Next(&gi)
-		if err != nil {
-			// The error here is empty
-			break
+	var fid *git.Oid
+	if len(from) != 0 {
+		if fid, err = r.resolveCommit(from); err != nil {
+			return nil, err
 		}
-
-		co, _ := r.repository.LookupCommit(&gi)
-		cmts = append(cmts, Commit{
-			Description: strings.TrimSpace(co.Message()),
-			Date:        co.Committer().When,
-			ID:          co.Id(),
-		})
 	}
 
-	return cmts, nil
+	return r.getCommitList(tid, fid)
+}
+
+// GetCommitListForHead returns the full history of HEAD.
+func (r *Repository) GetCommitListForHead() ([]Commit, error) {
+	return r.walkCommits(0, func(rv *git.RevWalk) error {
+		return rv.PushHead()
+	})
+}
+
+// GetLastCommits returns at most the given number of commits of HEAD,
+// the most recent first.
+func (r *Repository) GetLastCommits(n int) ([]Commit, error) {
+	return r.walkCommits(n, func(rv *git.RevWalk) error {
+		return rv.PushHead()
+	})
 }
 
-// GetOriginURL returns the repository's origin URL.
+// GetOriginURL returns the URL of the main remote, origin by default,
+// as configured: see the forge package to get its web URL.
 func (r *Repository) GetOriginURL() (string, error) {
 	cfg, err := r.repository.Config()
 	if err != nil {
 		return "", err
 	}
 
-	origin, err := cfg.LookupString("remote.origin.url")
-	if err != nil {
-		return "", err
-	}
-
-	origin = strings.Replace(origin, ".git", "", 1)
-	return origin, nil
+	return cfg.LookupString(fmt.Sprintf("remote.%s.url", r.GetRemotes()[0]))
 }
 
 // buildTag creates a Tag from the given details.
 func (r *Repository) buildTag(tn string, id *git.Oid) (Tag, error) {
 	var cd time.Time
+	var msg string
 
 	// LookupTag will resolve only annotated tags
 	tg, err := r.repository.LookupTag(id)
@@ -340,14 +467,142 @@ func (r *Repository) buildTag(tn string, id *git.Oid) (Tag, error) {
 		cd = co.Committer().When
 	} else {
 		cd = tg.Tagger().When
+		msg = strings.TrimSpace(tg.Message())
+	}
+
+	// Non-semver tags are kept, with an empty version
+	v, _ := semver.Parse(tn)
+
+	return Tag{Name: tn, Version: v, Date: cd, Message: msg, Target: id}, nil
+}
+
+// filterReachableTags returns only the tags pointing to HEAD
+// or to one of its ancestors.
+func (r *Repository) filterReachableTags(ts []Tag) ([]Tag, error) {
+	head, err := r.repository.Head()
+	if err != nil {
+		return nil, err
+	}
+	defer head.Free()
+
+	var rts []Tag
+	for _, tg := range ts {
+		cid, err := r.getTagCommitID(tg.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		if cid.Equal(head.Target()) {
+			rts = append(rts, tg)
+			continue
+		}
+
+		ok, err := r.repository.DescendantOf(head.Target(), cid)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rts = append(rts, tg)
+		}
+	}
+
+	return rts, nil
+}
+
+// getTagCommitID returns the ID of the commit targeted by the tag,
+// annotated tags are resolved.
+func (r *Repository) getTagCommitID(id *git.Oid) (*git.Oid, error) {
+	tg, err := r.repository.LookupTag(id)
+	if err != nil {
+		// Lightweight tags directly point to the commit
+		return id, nil
+	}
+	defer tg.Free()
+
+	return tg.TargetId(), nil
+}
+
+// resolveCommit returns the ID of the commit the given revision points to,
+// annotated tags are resolved.
+func (r *Repository) resolveCommit(rev string) (*git.Oid, error) {
+	obj, err := r.repository.RevparseSingle(rev)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Free()
+
+	co, err := obj.Peel(git.ObjectCommit)
+	if err != nil {
+		return nil, err
+	}
+	defer co.Free()
+
+	return co.Id(), nil
+}
+
+// getCommitList walks the history from the given starting point
+// and stops at the hidden one, which is excluded from the list.
+// Without hidden reference, the history is walked until its root.
+func (r *Repository) getCommitList(from *git.Oid, hide *git.Oid) ([]Commit, error) {
+	return r.walkCommits(0, func(rv *git.RevWalk) error {
+		// Start iterating from the given reference
+		if err := rv.Push(from); err != nil {
+			return err
+		}
+
+		if hide == nil {
+			return nil
+		}
+
+		// Iterate until the hidden reference
+		return rv.Hide(hide)
+	})
+}
+
+// walkCommits returns the commits of the rev walk configured by the given function,
+// at most limit of them unless it is 0.
+func (r *Repository) walkCommits(limit int, setup func(rv *git.RevWalk) error) ([]Commit, error) {
+	// Initialize and configure the rev walk
+	rv, err := r.repository.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer rv.Free()
+	rv.Sorting(git.SortTime)
+
+	if err = setup(rv); err != nil {
+		return nil, err
+	}
+
+	var cmts []Commit
+
+	var gi git.Oid
+	for limit == 0 || len(cmts) < limit {
+		err = rv.Next(&gi)
+		if err != nil {
+			// The error here is empty
+			break
+		}
+
+		co, err := r.repository.LookupCommit(&gi)
+		if err != nil {
+			return nil, err
+		}
+		cmts = append(cmts, Commit{
+			Description: strings.TrimSpace(co.Message()),
+			Date:        co.Committer().When,
+			ID:          co.Id(),
+		})
+		co.Free()
 	}
 
-	return Tag{Name: tn, Date: cd, Target: id}, nil
+	return cmts, nil
 }
 
-// getSSHPushURL returns the given URL formatted for SSH.
-func (r *Repository) getSSHPushURL(url string) (string, error) {
-	if !strings.HasPrefix(url, "http") {
+// getPushURL returns the given URL formatted for the push strategy,
+// HTTP URLs are rewritten for SSH strategies.
+func (r *Repository) getPushURL(url string) (string, error) {
+	if !strings.HasPrefix(url, "http") || !r.credentials.Push.isSSH() {
 		return url, nil
 	}
 
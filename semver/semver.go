@@ -3,25 +3,237 @@
 package semver
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 const (
-	Expr   = `(?i)^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(?:-[\da-z\-]+(\.[\da-z\-]+)*)?(\+[\da-z\-]+(?:\.[\da-z\-]+)*)?$`
+	Expr   = `(?i)^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(?:-([\da-z\-]+(?:\.[\da-z\-]+)*))?(?:\+([\da-z\-]+(?:\.[\da-z\-]+)*))?$`
 	Format = "%s.%s.%s"
+
+	coreFormat = "%d.%d.%d"
 )
 
-func IsValid(v string) bool {
-	r, err := regexp.Compile(Expr)
+var (
+	errInvalidVersion    = errors.New("The given version is not a valid semver version")
+	errInvalidIdentifier = errors.New("Numeric pre-release identifiers must not include leading zeroes")
+	errInvalidPreID      = errors.New("The pre-release identifier must be alphanumeric")
+	errNoPreID           = errors.New("A pre-release identifier is required")
+
+	rx    = regexp.MustCompile(Expr)
+	preRx = regexp.MustCompile(`^[0-9A-Za-z\-]*[A-Za-z\-][0-9A-Za-z\-]*$`)
+)
+
+// Version represents a semver 2.0 version number.
+type Version struct {
+	Major, Minor, Patch uint64
+	PreRelease          []string
+	Build               []string
+}
+
+// Parse creates a Version out of the given string,
+// the "v" prefix is tolerated.
+func Parse(v string) (Version, error) {
+	var err error
+	var ver Version
+
+	res := rx.FindStringSubmatch(v)
+	if len(res) == 0 {
+		return ver, errInvalidVersion
+	}
+
+	if ver.Major, err = strconv.ParseUint(res[1], 10, 64); err != nil {
+		return ver, err
+	}
+	if ver.Minor, err = strconv.ParseUint(res[2], 10, 64); err != nil {
+		return ver, err
+	}
+	if ver.Patch, err = strconv.ParseUint(res[3], 10, 64); err != nil {
+		return ver, err
+	}
+
+	if len(res[4]) != 0 {
+		ver.PreRelease = strings.Split(res[4], ".")
+		for _, id := range ver.PreRelease {
+			if isNumeric(id) && len(id) > 1 && id[0] == '0' {
+				return Version{}, errInvalidIdentifier
+			}
+		}
+	}
+
+	if len(res[5]) != 0 {
+		ver.Build = strings.Split(res[5], ".")
+	}
+
+	return ver, nil
+}
+
+// MustParse is like Parse but panics if the version cannot be parsed.
+func MustParse(v string) Version {
+	ver, err := Parse(v)
 	if err != nil {
-		return false
+		panic(err)
+	}
+	return ver
+}
+
+// String returns the version formatted as MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD].
+func (v Version) String() string {
+	s := fmt.Sprintf(coreFormat, v.Major, v.Minor, v.Patch)
+	if len(v.PreRelease) != 0 {
+		s += "-" + strings.Join(v.PreRelease, ".")
+	}
+	if len(v.Build) != 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 whether the version has a lower, equal or higher
+// precedence than the given one. The build metadata is ignored.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A version without pre-release has a higher precedence
+	switch {
+	case len(v.PreRelease) == 0 && len(o.PreRelease) == 0:
+		return 0
+	case len(v.PreRelease) == 0:
+		return 1
+	case len(o.PreRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.PreRelease) && i < len(o.PreRelease); i++ {
+		if c := compareIdentifier(v.PreRelease[i], o.PreRelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A larger set of pre-release fields has a higher precedence
+	return compareUint(uint64(len(v.PreRelease)), uint64(len(o.PreRelease)))
+}
+
+// LessThan tells whether the version has a lower precedence than the given one.
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// Equal tells whether both versions have the same precedence.
+func (v Version) Equal(o Version) bool {
+	return v.Compare(o) == 0
+}
+
+// NextMajor returns the next major version, ex. 1.2.3 => 2.0.0
+// A major pre-release is released, ex. 2.0.0-rc.1 => 2.0.0
+func (v Version) NextMajor() Version {
+	if v.IsPreRelease() && v.Minor == 0 && v.Patch == 0 {
+		return v.Release()
+	}
+	return Version{Major: v.Major + 1}
+}
+
+// NextMinor returns the next minor version, ex. 1.2.3 => 1.3.0
+// A minor pre-release is released, ex. 1.3.0-rc.1 => 1.3.0
+func (v Version) NextMinor() Version {
+	if v.IsPreRelease() && v.Patch == 0 {
+		return v.Release()
+	}
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// NextPatch returns the next patch version, ex. 1.2.3 => 1.2.4
+// A pre-release is released, ex. 1.2.3-rc.1 => 1.2.3
+func (v Version) NextPatch() Version {
+	if v.IsPreRelease() {
+		return v.Release()
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// NextPreMajor returns the next major pre-release version, ex. 1.2.3 => 2.0.0-rc.1
+// When the version is already a major pre-release, its counter is incremented.
+func (v Version) NextPreMajor(id string) (Version, error) {
+	return v.NextMajor().withPreRelease(v, id)
+}
+
+// NextPreMinor returns the next minor pre-release version, ex. 1.2.3 => 1.3.0-rc.1
+// When the version is already a minor pre-release, its counter is incremented.
+func (v Version) NextPreMinor(id string) (Version, error) {
+	return v.NextMinor().withPreRelease(v, id)
+}
+
+// NextPrePatch returns the next patch pre-release version, ex. 1.2.3 => 1.2.4-rc.1
+// When the version is already a pre-release, its counter is incremented.
+func (v Version) NextPrePatch(id string) (Version, error) {
+	return v.NextPatch().withPreRelease(v, id)
+}
+
+// NextPreRelease returns the next pre-release version, ex. 1.2.3-rc.1 => 1.2.3-rc.2
+// An empty identifier reuses the current one.
+func (v Version) NextPreRelease(id string) (Version, error) {
+	if len(id) == 0 {
+		if !v.IsPreRelease() {
+			return Version{}, errNoPreID
+		}
+		id = v.PreRelease[0]
+	}
+	return v.NextPrePatch(id)
+}
+
+// Release returns the version stripped of its pre-release and
+// build metadata, ex. 2.0.0-rc.2 => 2.0.0
+func (v Version) Release() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// IsPreRelease tells whether the version carries a pre-release.
+func (v Version) IsPreRelease() bool {
+	return len(v.PreRelease) != 0
+}
+
+// withPreRelease appends the given pre-release identifier and a counter,
+// incremented if the previous version already had the same pre-release.
+func (v Version) withPreRelease(prev Version, id string) (Version, error) {
+	if len(id) == 0 {
+		return Version{}, errNoPreID
+	}
+	if !preRx.MatchString(id) {
+		return Version{}, errInvalidPreID
 	}
-	res := r.MatchString(v)
-	return res
+
+	cnt := uint64(1)
+	if prev.Release().Equal(v) && len(prev.PreRelease) == 2 &&
+		prev.PreRelease[0] == id && isNumeric(prev.PreRelease[1]) {
+		pc, err := strconv.ParseUint(prev.PreRelease[1], 10, 64)
+		if err != nil {
+			return Version{}, err
+		}
+		cnt = pc + 1
+	}
+
+	v.PreRelease = []string{id, strconv.FormatUint(cnt, 10)}
+	return v, nil
+}
+
+// IsValid tells whether the given string is a valid semver version.
+func IsValid(v string) bool {
+	_, err := Parse(v)
+	return err == nil
 }
 
+// Deprecated: use Parse and Version.NextMajor instead.
 func GetNextMajorVersion(v string) (string, error) {
 	nv, err := getIncrementVersionByIndex(v, 1)
 	if err != nil {
@@ -30,6 +242,7 @@ func GetNextMajorVersion(v string) (string, error) {
 	return nv, nil
 }
 
+// Deprecated: use Parse and Version.NextMinor instead.
 func GetNextMinorVersion(v string) (string, error) {
 	nv, err := getIncrementVersionByIndex(v, 2)
 	if err != nil {
@@ -38,6 +251,7 @@ func GetNextMinorVersion(v string) (string, error) {
 	return nv, nil
 }
 
+// Deprecated: use Parse and Version.NextPatch instead.
 func GetNextPatchVersion(v string) (string, error) {
 	nv, err := getIncrementVersionByIndex(v, 3)
 	if err != nil {
@@ -49,13 +263,11 @@ func GetNextPatchVersion(v string) (string, error) {
 func getIncrementVersionByIndex(v string, i int) (string, error) {
 	var err error
 
-	r, err := regexp.Compile(Expr)
-	if err != nil {
-		return "", err
+	res := rx.FindStringSubmatch(v)
+	if len(res) == 0 {
+		return "", errInvalidVersion
 	}
 
-	res := r.FindStringSubmatch(v)
-
 	vInt, err := strconv.Atoi(res[i])
 	if err != nil {
 		return "", err
@@ -64,3 +276,45 @@ func getIncrementVersionByIndex(v string, i int) (string, error) {
 
 	return fmt.Sprintf(Format, res[1], res[2], res[3]), nil
 }
+
+// compareIdentifier compares two pre-release identifiers:
+// numeric ones are compared numerically and always have a lower
+// precedence than alphanumeric ones, compared lexically.
+func compareIdentifier(a, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+
+	switch {
+	case an && bn:
+		ai, _ := strconv.ParseUint(a, 10, 64)
+		bi, _ := strconv.ParseUint(b, 10, 64)
+		return compareUint(ai, bi)
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isNumeric(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
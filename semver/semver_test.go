@@ -56,3 +56,127 @@ func TestBumpPatchVersion(t *testing.T) {
 		So(mj, ShouldEqual, "9.9.10")
 	})
 }
+
+func TestParseVersion(t *testing.T) {
+	Convey("A full version should be deconstructed", t, func() {
+		v, err := semver.Parse("v1.2.3-rc.1+build.42")
+		So(err, ShouldBeNil)
+		So(v.Major, ShouldEqual, 1)
+		So(v.Minor, ShouldEqual, 2)
+		So(v.Patch, ShouldEqual, 3)
+		So(v.PreRelease, ShouldResemble, []string{"rc", "1"})
+		So(v.Build, ShouldResemble, []string{"build", "42"})
+		So(v.String(), ShouldEqual, "1.2.3-rc.1+build.42")
+	})
+
+	Convey("Invalid versions should be refused", t, func() {
+		_, err := semver.Parse("1.2")
+		So(err, ShouldNotBeNil)
+
+		_, err = semver.Parse("01.2.3")
+		So(err, ShouldNotBeNil)
+
+		_, err = semver.Parse("1.2.3-rc.01")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestVersionPrecedence(t *testing.T) {
+	Convey("Versions should be ordered following the semver precedence", t, func() {
+		ordered := []string{
+			"1.0.0-alpha",
+			"1.0.0-alpha.1",
+			"1.0.0-alpha.beta",
+			"1.0.0-beta",
+			"1.0.0-beta.2",
+			"1.0.0-beta.11",
+			"1.0.0-rc.1",
+			"1.0.0",
+			"1.0.1",
+			"1.10.0",
+			"2.0.0",
+		}
+
+		for i := 1; i < len(ordered); i++ {
+			prev, cur := semver.MustParse(ordered[i-1]), semver.MustParse(ordered[i])
+			So(prev.LessThan(cur), ShouldBeTrue)
+			So(cur.Compare(prev), ShouldEqual, 1)
+		}
+	})
+
+	Convey("The build metadata should be ignored", t, func() {
+		So(semver.MustParse("1.0.0+foo").Equal(semver.MustParse("1.0.0+bar")), ShouldBeTrue)
+	})
+}
+
+func TestNextVersion(t *testing.T) {
+	Convey("The lower digits should be reset", t, func() {
+		v := semver.MustParse("9.9.9+build.1")
+		So(v.NextMajor().String(), ShouldEqual, "10.0.0")
+		So(v.NextMinor().String(), ShouldEqual, "9.10.0")
+		So(v.NextPatch().String(), ShouldEqual, "9.9.10")
+	})
+
+	Convey("A pre-release should be released at its bump level", t, func() {
+		v := semver.MustParse("1.2.3-rc.1")
+		So(v.NextMajor().String(), ShouldEqual, "2.0.0")
+		So(v.NextMinor().String(), ShouldEqual, "1.3.0")
+		So(v.NextPatch().String(), ShouldEqual, "1.2.3")
+
+		v = semver.MustParse("1.3.0-rc.1")
+		So(v.NextMajor().String(), ShouldEqual, "2.0.0")
+		So(v.NextMinor().String(), ShouldEqual, "1.3.0")
+		So(v.NextPatch().String(), ShouldEqual, "1.3.0")
+
+		v = semver.MustParse("2.0.0-rc.1")
+		So(v.NextMajor().String(), ShouldEqual, "2.0.0")
+		So(v.NextMinor().String(), ShouldEqual, "2.0.0")
+		So(v.NextPatch().String(), ShouldEqual, "2.0.0")
+	})
+}
+
+func TestNextPreReleaseVersion(t *testing.T) {
+	Convey("A pre-release should be appended to the next version", t, func() {
+		v := semver.MustParse("1.2.3")
+
+		nv, err := v.NextPreMajor("rc")
+		So(err, ShouldBeNil)
+		So(nv.String(), ShouldEqual, "2.0.0-rc.1")
+
+		nv, err = v.NextPreMinor("beta")
+		So(err, ShouldBeNil)
+		So(nv.String(), ShouldEqual, "1.3.0-beta.1")
+
+		nv, err = v.NextPrePatch("alpha")
+		So(err, ShouldBeNil)
+		So(nv.String(), ShouldEqual, "1.2.4-alpha.1")
+	})
+
+	Convey("The counter should be incremented for the same identifier", t, func() {
+		v := semver.MustParse("2.0.0-rc.1")
+
+		nv, err := v.NextPreMajor("rc")
+		So(err, ShouldBeNil)
+		So(nv.String(), ShouldEqual, "2.0.0-rc.2")
+
+		nv, err = v.NextPreRelease("")
+		So(err, ShouldBeNil)
+		So(nv.String(), ShouldEqual, "2.0.0-rc.2")
+
+		nv, err = semver.MustParse("2.0.0-beta.3").NextPreRelease("rc")
+		So(err, ShouldBeNil)
+		So(nv.String(), ShouldEqual, "2.0.0-rc.1")
+	})
+
+	Convey("Invalid identifiers should be refused", t, func() {
+		_, err := semver.MustParse("1.2.3").NextPreRelease("")
+		So(err, ShouldNotBeNil)
+
+		_, err = semver.MustParse("1.2.3").NextPrePatch("r.c")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("The pre-release should be stripped on release", t, func() {
+		So(semver.MustParse("2.0.0-rc.2+build.1").Release().String(), ShouldEqual, "2.0.0")
+	})
+}